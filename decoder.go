@@ -1,6 +1,15 @@
 package envconfig
 
 import (
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
 	"reflect"
 	"strconv"
 	"strings"
@@ -58,16 +67,169 @@ var defaultDecoders = map[reflect.Type]DecoderFunc{
 
 		return reflect.ValueOf(floatValue), nil
 	},
+	reflect.TypeOf(url.URL{}): func(key, value string) (reflect.Value, error) {
+		urlValue, err := parseURL(value)
+		if err != nil {
+			return reflect.Value{}, &FieldConversionError{
+				FieldName:  key,
+				TargetType: "url.URL",
+				Err:        err,
+			}
+		}
+
+		return reflect.ValueOf(*urlValue), nil
+	},
+	reflect.TypeOf(&url.URL{}): func(key, value string) (reflect.Value, error) {
+		urlValue, err := parseURL(value)
+		if err != nil {
+			return reflect.Value{}, &FieldConversionError{
+				FieldName:  key,
+				TargetType: "*url.URL",
+				Err:        err,
+			}
+		}
+
+		return reflect.ValueOf(urlValue), nil
+	},
+	reflect.TypeOf(net.IP{}): func(key, value string) (reflect.Value, error) {
+		ipValue := net.ParseIP(value)
+		if ipValue == nil {
+			return reflect.Value{}, &FieldConversionError{
+				FieldName:  key,
+				TargetType: "net.IP",
+				Err:        &net.ParseError{Type: "IP address", Text: value},
+			}
+		}
+
+		return reflect.ValueOf(ipValue), nil
+	},
+	reflect.TypeOf(net.IPNet{}): func(key, value string) (reflect.Value, error) {
+		_, ipNetValue, err := net.ParseCIDR(value)
+		if err != nil {
+			return reflect.Value{}, &FieldConversionError{
+				FieldName:  key,
+				TargetType: "net.IPNet",
+				Err:        err,
+			}
+		}
+
+		return reflect.ValueOf(*ipNetValue), nil
+	},
+	reflect.TypeOf(netip.AddrPort{}): func(key, value string) (reflect.Value, error) {
+		addrPortValue, err := netip.ParseAddrPort(value)
+		if err != nil {
+			return reflect.Value{}, &FieldConversionError{
+				FieldName:  key,
+				TargetType: "netip.AddrPort",
+				Err:        err,
+			}
+		}
+
+		return reflect.ValueOf(addrPortValue), nil
+	},
+	reflect.TypeOf(netip.Prefix{}): func(key, value string) (reflect.Value, error) {
+		prefixValue, err := netip.ParsePrefix(value)
+		if err != nil {
+			return reflect.Value{}, &FieldConversionError{
+				FieldName:  key,
+				TargetType: "netip.Prefix",
+				Err:        err,
+			}
+		}
+
+		return reflect.ValueOf(prefixValue), nil
+	},
+	reflect.TypeOf(mail.Address{}): func(key, value string) (reflect.Value, error) {
+		addressValue, err := mail.ParseAddress(value)
+		if err != nil {
+			return reflect.Value{}, &FieldConversionError{
+				FieldName:  key,
+				TargetType: "mail.Address",
+				Err:        err,
+			}
+		}
+
+		return reflect.ValueOf(*addressValue), nil
+	},
+	reflect.TypeOf(time.Time{}): timeDecoder(""),
+	reflect.TypeOf(&time.Location{}): func(key, value string) (reflect.Value, error) {
+		locationValue, err := time.LoadLocation(value)
+		if err != nil {
+			return reflect.Value{}, &FieldConversionError{
+				FieldName:  key,
+				TargetType: "*time.Location",
+				Err:        err,
+			}
+		}
+
+		return reflect.ValueOf(locationValue), nil
+	},
+}
+
+// timeDecoder returns a decoder for time.Time using the provided layout.
+//
+// When layout is empty, values are parsed as RFC 3339, falling back to Unix seconds.
+func timeDecoder(layout string) DecoderFunc {
+	return func(key, value string) (reflect.Value, error) {
+		if layout == "" {
+			if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+				return reflect.ValueOf(time.Unix(seconds, 0).UTC()), nil
+			}
+		}
+
+		parseLayout := layout
+		if parseLayout == "" {
+			parseLayout = time.RFC3339
+		}
+
+		timeValue, err := time.Parse(parseLayout, value)
+		if err != nil {
+			return reflect.Value{}, &FieldConversionError{
+				FieldName:  key,
+				TargetType: "time.Time",
+				Err:        err,
+			}
+		}
+
+		return reflect.ValueOf(timeValue), nil
+	}
+}
+
+// parseURL parses an absolute URL, rejecting values without a scheme, which url.Parse would otherwise accept as a
+// relative path.
+func parseURL(value string) (*url.URL, error) {
+	urlValue, err := url.Parse(value)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // Wrapped by the calling decoder.
+	}
+
+	if urlValue.Scheme == "" {
+		return nil, ErrMissingScheme
+	}
+
+	return urlValue, nil
 }
 
 type Setter interface {
 	Set(value string) error
 }
 
+// decoder returns the decoder for typ, taking into account any tags on the field that alter decoding.
+func (s settings) decoder(typ reflect.Type, d fieldDescriptor) (DecoderFunc, bool) {
+	if d.layout != "" && typ == reflect.TypeOf(time.Time{}) {
+		return timeDecoder(d.layout), true
+	}
+
+	dec, ok := s.decoders[typ]
+
+	return dec, ok
+}
+
 // setFieldValue determines the type of a config field, and branch out to the correct
 // function to populate that data type.
 func (s settings) setFieldValue(
 	configFieldValue reflect.Value,
+	d fieldDescriptor,
 	entry entry,
 ) error {
 	fieldAddr := configFieldValue.Addr()
@@ -76,7 +238,7 @@ func (s settings) setFieldValue(
 		return setter.Set(entry.value)
 	}
 
-	if dec, ok := s.decoders[configFieldValue.Type()]; ok {
+	if dec, ok := s.decoder(configFieldValue.Type(), d); ok {
 		decodedValue, err := dec(entry.key, entry.value)
 		if err != nil {
 			return err
@@ -88,21 +250,88 @@ func (s settings) setFieldValue(
 	switch configFieldValue.Interface().(type) {
 	case string:
 		configFieldValue.SetString(entry.value)
+	case []byte:
+		return setBytesFieldValue(configFieldValue, d, entry)
 	case []string:
-		return setStringSliceFieldValue(configFieldValue, entry.value)
+		return setStringSliceFieldValue(configFieldValue, entry.value, d.separator)
 	case []int:
-		return setIntSliceFieldValue(configFieldValue, entry)
+		return setIntSliceFieldValue(configFieldValue, entry, d.separator)
 	case []float64:
-		return setFloatSliceFieldValue(configFieldValue, entry)
+		return setFloatSliceFieldValue(configFieldValue, entry, d.separator)
+	default:
+		if configFieldValue.Kind() == reflect.Slice {
+			if dec, ok := s.decoder(configFieldValue.Type().Elem(), d); ok {
+				return setDecodedSliceFieldValue(configFieldValue, dec, entry, d.separator)
+			}
+		}
+
+		return setUnmarshalerFieldValue(configFieldValue, entry)
+	}
+
+	return nil
+}
+
+// decodable reports whether values of typ are populated from a single value, rather than being treated as a
+// nested config struct.
+func (s settings) decodable(typ reflect.Type) bool {
+	if _, ok := s.decoders[typ]; ok {
+		return true
+	}
+
+	addr := reflect.PointerTo(typ)
+
+	return addr.Implements(reflect.TypeFor[Setter]()) ||
+		addr.Implements(reflect.TypeFor[encoding.TextUnmarshaler]()) ||
+		addr.Implements(reflect.TypeFor[encoding.BinaryUnmarshaler]()) ||
+		addr.Implements(reflect.TypeFor[json.Unmarshaler]())
+}
+
+// setUnmarshalerFieldValue populates fields whose type implements one of the standard library unmarshaling
+// interfaces, trying encoding.TextUnmarshaler, then encoding.BinaryUnmarshaler, then json.Unmarshaler.
+//
+// Pointer fields are allocated when their element type implements one of the interfaces.
+func setUnmarshalerFieldValue(configFieldValue reflect.Value, entry entry) error {
+	target := configFieldValue.Addr()
+	if configFieldValue.Kind() == reflect.Pointer {
+		target = reflect.New(configFieldValue.Type().Elem())
+	}
+
+	var err error
+
+	switch unmarshaler := target.Interface().(type) {
+	case encoding.TextUnmarshaler:
+		err = unmarshaler.UnmarshalText([]byte(entry.value))
+	case encoding.BinaryUnmarshaler:
+		err = unmarshaler.UnmarshalBinary([]byte(entry.value))
+	case json.Unmarshaler:
+		data := []byte(entry.value)
+		if !json.Valid(data) {
+			// Treat values that are not JSON documents as JSON strings.
+			data, _ = json.Marshal(entry.value) //nolint:errchkjson // Marshaling a string cannot fail.
+		}
+
+		err = unmarshaler.UnmarshalJSON(data)
 	default:
 		return &UnsupportedFieldTypeError{FieldType: configFieldValue.Interface()}
 	}
 
+	if err != nil {
+		return &FieldConversionError{
+			FieldName:  entry.key,
+			TargetType: configFieldValue.Type().String(),
+			Err:        err,
+		}
+	}
+
+	if configFieldValue.Kind() == reflect.Pointer {
+		configFieldValue.Set(target)
+	}
+
 	return nil
 }
 
-func setStringSliceFieldValue(configFieldValue reflect.Value, environmentValue string) error {
-	values := strings.Split(environmentValue, ",")
+func setStringSliceFieldValue(configFieldValue reflect.Value, environmentValue, separator string) error {
+	values := strings.Split(environmentValue, separator)
 	slice := reflect.MakeSlice(configFieldValue.Type(), len(values), len(values))
 
 	for i, v := range values {
@@ -115,11 +344,81 @@ func setStringSliceFieldValue(configFieldValue reflect.Value, environmentValue s
 	return nil
 }
 
+// setBytesFieldValue populates a []byte field, decoding the value using the encoding tag.
+func setBytesFieldValue(configFieldValue reflect.Value, d fieldDescriptor, entry entry) error {
+	var (
+		decoded []byte
+		err     error
+	)
+
+	switch d.encoding {
+	case "", "raw":
+		decoded = []byte(entry.value)
+	case "base64":
+		decoded, err = decodeBase64(base64.StdEncoding, entry.value)
+	case "base64url":
+		decoded, err = decodeBase64(base64.URLEncoding, entry.value)
+	case "hex":
+		decoded, err = hex.DecodeString(strings.TrimSpace(entry.value))
+	default:
+		return &InvalidOptionConversionError{
+			FieldName: entry.key,
+			Option:    tagEncoding,
+			Err:       fmt.Errorf("%w: %q", ErrUnknownEncoding, d.encoding),
+		}
+	}
+
+	if err != nil {
+		return &FieldConversionError{
+			FieldName:  entry.key,
+			TargetType: "[]byte",
+			Err:        err,
+		}
+	}
+
+	configFieldValue.SetBytes(decoded)
+
+	return nil
+}
+
+// decodeBase64 decodes a base64 value, accepting values with or without padding.
+func decodeBase64(enc *base64.Encoding, value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+
+	if strings.HasSuffix(value, string(base64.StdPadding)) {
+		return enc.DecodeString(value) //nolint:wrapcheck // Wrapped by the caller.
+	}
+
+	return enc.WithPadding(base64.NoPadding).DecodeString(value) //nolint:wrapcheck // Wrapped by the caller.
+}
+
+// setDecodedSliceFieldValue populates a slice field whose element type has a registered decoder.
+func setDecodedSliceFieldValue(configFieldValue reflect.Value, dec DecoderFunc, entry entry, separator string) error {
+	values := strings.Split(entry.value, separator)
+	slice := reflect.MakeSlice(configFieldValue.Type(), len(values), len(values))
+
+	for i, v := range values {
+		v = strings.TrimSpace(v)
+
+		decodedValue, err := dec(entry.key, v)
+		if err != nil {
+			return err
+		}
+
+		slice.Index(i).Set(decodedValue)
+	}
+
+	configFieldValue.Set(slice)
+
+	return nil
+}
+
 func setIntSliceFieldValue(
 	configFieldValue reflect.Value,
 	entry entry,
+	separator string,
 ) error {
-	values := strings.Split(entry.value, ",")
+	values := strings.Split(entry.value, separator)
 	slice := reflect.MakeSlice(configFieldValue.Type(), len(values), len(values))
 
 	for i, v := range values {
@@ -145,8 +444,9 @@ func setIntSliceFieldValue(
 func setFloatSliceFieldValue(
 	configFieldValue reflect.Value,
 	entry entry,
+	separator string,
 ) error {
-	values := strings.Split(entry.value, ",")
+	values := strings.Split(entry.value, separator)
 	slice := reflect.MakeSlice(configFieldValue.Type(), len(values), len(values))
 
 	for i, v := range values {
@@ -0,0 +1,346 @@
+package envconfig_test
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/h-dav/envconfig/v3"
+)
+
+// jsonList only implements json.Unmarshaler, to exercise the final unmarshaler fallback.
+type jsonList struct {
+	Items []string
+}
+
+func (l *jsonList) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &l.Items)
+}
+
+func TestSetSuccessWithUnmarshalerFields(t *testing.T) {
+	type Config struct {
+		Addr    netip.Addr `env:"UNMARSHALER_ADDR"`
+		Level   slog.Level `env:"UNMARSHALER_LEVEL"`
+		Big     *big.Int   `env:"UNMARSHALER_BIG"`
+		JSONish jsonList   `env:"UNMARSHALER_JSON"`
+	}
+
+	t.Setenv("UNMARSHALER_ADDR", "10.0.0.1")
+	t.Setenv("UNMARSHALER_LEVEL", "warn")
+	t.Setenv("UNMARSHALER_BIG", "123456789012345678901234567890")
+	t.Setenv("UNMARSHALER_JSON", `["a","b"]`)
+
+	var config Config
+
+	if err := envconfig.Set(&config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := netip.MustParseAddr("10.0.0.1"); config.Addr != want {
+		t.Errorf("got %v, want %v", config.Addr, want)
+	}
+
+	if config.Level != slog.LevelWarn {
+		t.Errorf("got %v, want %v", config.Level, slog.LevelWarn)
+	}
+
+	if want, _ := new(big.Int).SetString("123456789012345678901234567890", 10); config.Big.Cmp(want) != 0 {
+		t.Errorf("got %v, want %v", config.Big, want)
+	}
+
+	if len(config.JSONish.Items) != 2 || config.JSONish.Items[1] != "b" {
+		t.Errorf("got %+v, want [a b]", config.JSONish.Items)
+	}
+}
+
+func TestSetFailureWithInvalidUnmarshalerField(t *testing.T) {
+	type Config struct {
+		Addr netip.Addr `env:"UNMARSHALER_INVALID_ADDR"`
+	}
+
+	t.Setenv("UNMARSHALER_INVALID_ADDR", "not-an-ip")
+
+	var config Config
+
+	err := envconfig.Set(&config)
+
+	var conversionErr *envconfig.FieldConversionError
+	if !errors.As(err, &conversionErr) {
+		t.Fatalf("got %v, want FieldConversionError", err)
+	}
+
+	if conversionErr.FieldName != "UNMARSHALER_INVALID_ADDR" {
+		t.Errorf("got %v, want UNMARSHALER_INVALID_ADDR", conversionErr.FieldName)
+	}
+}
+
+func TestSetSuccessWithNetworkFields(t *testing.T) {
+	type Config struct {
+		URL      url.URL        `env:"NETWORK_URL"`
+		URLPtr   *url.URL       `env:"NETWORK_URL_PTR" scheme:"https, postgres"`
+		IP       net.IP         `env:"NETWORK_IP"`
+		IPNet    net.IPNet      `env:"NETWORK_IP_NET"`
+		AddrPort netip.AddrPort `env:"NETWORK_ADDR_PORT"`
+		Prefix   netip.Prefix   `env:"NETWORK_PREFIX"`
+		Address  mail.Address   `env:"NETWORK_ADDRESS"`
+	}
+
+	t.Setenv("NETWORK_URL", "http://example.com/path")
+	t.Setenv("NETWORK_URL_PTR", "postgres://user@db:5432/app")
+	t.Setenv("NETWORK_IP", "192.168.0.1")
+	t.Setenv("NETWORK_IP_NET", "10.0.0.0/8")
+	t.Setenv("NETWORK_ADDR_PORT", "127.0.0.1:8080")
+	t.Setenv("NETWORK_PREFIX", "fd00::/8")
+	t.Setenv("NETWORK_ADDRESS", "Ops <ops@example.com>")
+
+	var config Config
+
+	if err := envconfig.Set(&config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if config.URL.Host != "example.com" || config.URLPtr.Host != "db:5432" {
+		t.Errorf("got %v and %v, want example.com and db:5432", config.URL.Host, config.URLPtr.Host)
+	}
+
+	if !config.IP.Equal(net.IPv4(192, 168, 0, 1)) || config.IPNet.String() != "10.0.0.0/8" {
+		t.Errorf("got %v and %v, want 192.168.0.1 and 10.0.0.0/8", config.IP, config.IPNet.String())
+	}
+
+	if config.AddrPort.Port() != 8080 || config.Prefix.Bits() != 8 {
+		t.Errorf("got %v and %v, want port 8080 and 8 bits", config.AddrPort, config.Prefix)
+	}
+
+	if config.Address.Address != "ops@example.com" {
+		t.Errorf("got %v, want ops@example.com", config.Address.Address)
+	}
+}
+
+func TestSetFailureWithNetworkFields(t *testing.T) {
+	testCases := map[string]struct {
+		config any
+		value  string
+		want   error
+	}{
+		"invalid ip": {
+			config: &struct {
+				IP net.IP `env:"NETWORK_INVALID"`
+			}{},
+			value: "999.0.0.1",
+		},
+		"url without scheme": {
+			config: &struct {
+				URL url.URL `env:"NETWORK_INVALID"`
+			}{},
+			value: "example.com",
+			want:  envconfig.ErrMissingScheme,
+		},
+		"url with disallowed scheme": {
+			config: &struct {
+				URL *url.URL `env:"NETWORK_INVALID" scheme:"https"`
+			}{},
+			value: "http://example.com",
+			want:  envconfig.ErrSchemeNotAllowed,
+		},
+		"url slice with disallowed scheme": {
+			config: &struct {
+				URLs []url.URL `env:"NETWORK_INVALID" scheme:"https"`
+			}{},
+			value: "https://example.com,http://example.com",
+			want:  envconfig.ErrSchemeNotAllowed,
+		},
+		"url pointer slice with disallowed scheme": {
+			config: &struct {
+				URLs []*url.URL `env:"NETWORK_INVALID" scheme:"https"`
+			}{},
+			value: "https://example.com,http://example.com",
+			want:  envconfig.ErrSchemeNotAllowed,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			t.Setenv("NETWORK_INVALID", tc.value)
+
+			err := envconfig.Set(tc.config)
+
+			var conversionErr *envconfig.FieldConversionError
+			if !errors.As(err, &conversionErr) {
+				t.Fatalf("got %v, want FieldConversionError", err)
+			}
+
+			if tc.want != nil && !errors.Is(err, tc.want) {
+				t.Errorf("got %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestSetFailureWithSchemeTagOnUnsupportedType(t *testing.T) {
+	var config struct {
+		Endpoint string `env:"NETWORK_SCHEME_STRING" scheme:"https"`
+	}
+
+	err := envconfig.Set(&config)
+
+	var optionErr *envconfig.InvalidOptionConversionError
+	if !errors.As(err, &optionErr) || !errors.Is(err, envconfig.ErrUnsupportedRule) {
+		t.Errorf("got %v, want InvalidOptionConversionError wrapping %v", err, envconfig.ErrUnsupportedRule)
+	}
+}
+
+func TestSetSuccessWithTimeFields(t *testing.T) {
+	type Config struct {
+		Launch      time.Time      `env:"TIME_LAUNCH"`
+		Epoch       time.Time      `env:"TIME_EPOCH"`
+		Maintenance time.Time      `env:"TIME_MAINTENANCE" layout:"2006-01-02"`
+		Holidays    []time.Time    `env:"TIME_HOLIDAYS" layout:"2006-01-02"`
+		Location    *time.Location `env:"TIME_LOCATION"`
+		Nested      struct {
+			Deadline time.Time `env:"DEADLINE" layout:"02/01/2006"`
+		} `prefix:"TIME_NESTED_"`
+	}
+
+	t.Setenv("TIME_LAUNCH", "2024-05-01T09:30:00Z")
+	t.Setenv("TIME_EPOCH", "1700000000")
+	t.Setenv("TIME_MAINTENANCE", "2024-12-24")
+	t.Setenv("TIME_HOLIDAYS", "2024-12-25, 2024-12-26")
+	t.Setenv("TIME_LOCATION", "Europe/London")
+	t.Setenv("TIME_NESTED_DEADLINE", "31/01/2025")
+
+	var config Config
+
+	if err := envconfig.Set(&config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := time.Date(2024, time.May, 1, 9, 30, 0, 0, time.UTC); !config.Launch.Equal(want) {
+		t.Errorf("got %v, want %v", config.Launch, want)
+	}
+
+	if want := time.Unix(1700000000, 0); !config.Epoch.Equal(want) {
+		t.Errorf("got %v, want %v", config.Epoch, want)
+	}
+
+	if want := time.Date(2024, time.December, 24, 0, 0, 0, 0, time.UTC); !config.Maintenance.Equal(want) {
+		t.Errorf("got %v, want %v", config.Maintenance, want)
+	}
+
+	if len(config.Holidays) != 2 || config.Holidays[1].Day() != 26 {
+		t.Errorf("got %v, want two holidays ending on the 26th", config.Holidays)
+	}
+
+	if config.Location.String() != "Europe/London" {
+		t.Errorf("got %v, want Europe/London", config.Location)
+	}
+
+	if want := time.Date(2025, time.January, 31, 0, 0, 0, 0, time.UTC); !config.Nested.Deadline.Equal(want) {
+		t.Errorf("got %v, want %v", config.Nested.Deadline, want)
+	}
+}
+
+func TestSetFailureWithInvalidTimeLayout(t *testing.T) {
+	type Config struct {
+		Maintenance time.Time `env:"TIME_INVALID" layout:"2006-01-02"`
+	}
+
+	t.Setenv("TIME_INVALID", "2024-05-01T09:30:00Z")
+
+	var config Config
+
+	var conversionErr *envconfig.FieldConversionError
+	if err := envconfig.Set(&config); !errors.As(err, &conversionErr) {
+		t.Fatalf("got %v, want FieldConversionError", err)
+	}
+}
+
+func TestSetSuccessWithBytesFields(t *testing.T) {
+	type Config struct {
+		Raw        []byte `env:"BYTES_RAW"`
+		Base64     []byte `env:"BYTES_BASE64" encoding:"base64"`
+		Base64URL  []byte `env:"BYTES_BASE64_URL" encoding:"base64url"`
+		Hex        []byte `env:"BYTES_HEX" encoding:"hex"`
+		PEM        []byte `env:"BYTES_PEM_FILE" fromfile:"true"`
+		FileBase64 []byte `env:"BYTES_BASE64_FILE" fromfile:"true" encoding:"base64"`
+	}
+
+	t.Setenv("BYTES_RAW", "plain")
+	t.Setenv("BYTES_BASE64", "aGVsbG8=")
+	t.Setenv("BYTES_BASE64_URL", "_-8")
+	t.Setenv("BYTES_HEX", "cafe")
+	t.Setenv("BYTES_PEM_FILE", "./test_data/success_with_fromfile_field.pem")
+	t.Setenv("BYTES_BASE64_FILE", "./test_data/success_with_fromfile_base64_field.txt")
+
+	var config Config
+
+	if err := envconfig.Set(&config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pem, err := os.ReadFile("./test_data/success_with_fromfile_field.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Config{
+		Raw:        []byte("plain"),
+		Base64:     []byte("hello"),
+		Base64URL:  []byte{0xff, 0xef},
+		Hex:        []byte{0xca, 0xfe},
+		PEM:        pem,
+		FileBase64: []byte("hello world"),
+	}
+
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("got %+v, want %+v", config, want)
+	}
+}
+
+func TestSetFailureWithBytesFields(t *testing.T) {
+	testCases := map[string]struct {
+		config any
+		value  string
+		want   any
+	}{
+		"invalid hex": {
+			config: &struct {
+				Hex []byte `env:"BYTES_INVALID" encoding:"hex"`
+			}{},
+			value: "xyz",
+			want:  new(*envconfig.FieldConversionError),
+		},
+		"unknown encoding": {
+			config: &struct {
+				Bytes []byte `env:"BYTES_INVALID" encoding:"base32"`
+			}{},
+			value: "abc",
+			want:  new(*envconfig.InvalidOptionConversionError),
+		},
+		"missing file": {
+			config: &struct {
+				Bytes []byte `env:"BYTES_INVALID" fromfile:"true"`
+			}{},
+			value: "./test_data/does_not_exist.pem",
+			want:  new(*envconfig.FileReadError),
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			t.Setenv("BYTES_INVALID", tc.value)
+
+			if err := envconfig.Set(tc.config); !errors.As(err, tc.want) {
+				t.Errorf("got %v, want %T", err, tc.want)
+			}
+		})
+	}
+}
@@ -4,9 +4,12 @@ package envconfig
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -14,14 +17,33 @@ type entry struct {
 	key, value string
 }
 
+// nestedStructKey identifies a struct being populated by its type and prefix.
+type nestedStructKey struct {
+	typ    reflect.Type
+	prefix string
+}
+
+// builder is implemented by nested config structs that derive additional state once populated, such as TLS.
+type builder interface {
+	build(prefix string) error
+}
+
+// locationDefault is the location of values provided by the default tag.
+const locationDefault = "default"
+
 // textReplacementRegex is used to detect text replacement in environment variables.
 var textReplacementRegex = regexp.MustCompile(`\${[^}]+}`)
 
 // Set will parse multiple sources for config values, and use these values to populate the passed in config struct.
 func Set(config any, opts ...option) error {
 	s := &settings{
-		source:   map[string]string{},
-		decoders: defaultDecoders,
+		source:     map[string]string{},
+		locations:  map[string]string{},
+		origins:    map[string]string{},
+		overridden: map[string][]SourceValue{},
+		consumed:   map[string]bool{},
+		populating: map[nestedStructKey]bool{},
+		decoders:   maps.Clone(defaultDecoders),
 	}
 
 	for _, opt := range opts {
@@ -45,13 +67,30 @@ func Set(config any, opts ...option) error {
 	}
 
 	for _, source := range s.sources {
-		values, err := source.Load()
+		values, locations, err := source.locate()
 		if err != nil {
 			return fmt.Errorf("load from source: %w", err)
 		}
 
+		values, locations, collisions := s.canonicalKeys(values, locations)
+		s.collisions = append(s.collisions, collisions...)
+
 		for key, value := range values {
+			if previous, ok := s.source[key]; ok {
+				s.overridden[key] = append(s.overridden[key], SourceValue{
+					Source:   s.origins[key],
+					Location: s.locations[key],
+					Value:    previous,
+				})
+			}
+
 			s.source[key] = value
+			s.locations[key] = locations[key]
+			s.origins[key] = source.name()
+		}
+
+		if s.strict {
+			s.strictKeys = append(s.strictKeys, s.strictSourceKeys(source, values)...)
 		}
 	}
 
@@ -62,6 +101,110 @@ func Set(config any, opts ...option) error {
 	return nil
 }
 
+// canonicalKey returns the key used in settings.source for key. When WithKeyNormalization() is used, keys are upper
+// case, with '.' and '-' replaced by '_', otherwise key is returned unchanged.
+func (s settings) canonicalKey(key string) string {
+	if !s.normalizeKeys {
+		return key
+	}
+
+	return strings.ToUpper(keySeparatorReplacer.Replace(key))
+}
+
+// keySeparatorReplacer replaces the separators treated as equivalent to '_' by WithKeyNormalization().
+var keySeparatorReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+// canonicalKeys returns values and locations from a single source with canonical keys, along with a
+// *KeyCollisionError for every canonical key shared by two or more distinct keys with different values.
+//
+// Collisions are only reported once the config struct is populated, by collisionErrors, as unrelated keys from the
+// environment, such as http_proxy and HTTP_PROXY, commonly collide.
+func (s settings) canonicalKeys(
+	values, locations map[string]string,
+) (map[string]string, map[string]string, []*KeyCollisionError) {
+	if !s.normalizeKeys {
+		return values, locations, nil
+	}
+
+	canonicalValues := make(map[string]string, len(values))
+	canonicalLocations := make(map[string]string, len(values))
+	rawKeys := make(map[string]string, len(values))
+	collisions := make(map[string]*KeyCollisionError)
+
+	var ordered []*KeyCollisionError
+
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		canonical := s.canonicalKey(key)
+
+		if rawKey, ok := rawKeys[canonical]; ok && values[rawKey] != values[key] {
+			collision, ok := collisions[canonical]
+			if !ok {
+				collision = &KeyCollisionError{
+					Key:       canonical,
+					RawKeys:   []string{rawKey},
+					Locations: []string{locations[rawKey]},
+				}
+				collisions[canonical] = collision
+				ordered = append(ordered, collision)
+			}
+
+			collision.RawKeys = append(collision.RawKeys, key)
+			collision.Locations = append(collision.Locations, locations[key])
+		}
+
+		canonicalValues[canonical] = values[key]
+		canonicalLocations[canonical] = locations[key]
+		rawKeys[canonical] = key
+	}
+
+	return canonicalValues, canonicalLocations, ordered
+}
+
+// collisionErrors returns the key collisions which affect the config struct, being those for keys looked up while
+// populating it, or under the prefix provided using WithPrefix().
+func (s settings) collisionErrors() []error {
+	var errs []error
+
+	for _, collision := range s.collisions {
+		if s.consumed[collision.Key] || (s.prefix != "" && strings.HasPrefix(collision.Key, s.canonicalKey(s.prefix))) {
+			errs = append(errs, collision)
+		}
+	}
+
+	return errs
+}
+
+// lookup returns the value of key from settings.source, and records the key as consumed for WithStrict().
+func (s settings) lookup(key string) string {
+	key = s.canonicalKey(key)
+
+	if s.consumed != nil {
+		s.consumed[key] = true
+	}
+
+	return s.source[key]
+}
+
+// location returns where the value of key in settings.source came from, e.g. env or config/prod.env:14.
+func (s settings) location(key string) string {
+	return s.locations[s.canonicalKey(key)]
+}
+
+// keysWithPrefix returns the remainder of every key in settings.source which starts with prefix.
+func (s settings) keysWithPrefix(prefix string) []string {
+	prefix = s.canonicalKey(prefix)
+
+	var rests []string
+
+	for key := range s.source {
+		if rest, found := strings.CutPrefix(key, prefix); found {
+			rests = append(rests, rest)
+		}
+	}
+
+	return rests
+}
+
 // populateStruct uses the items in settings.source to populate the passed in config struct.
 func (s settings) populateStruct(config any) error {
 	configStruct := reflect.ValueOf(config)
@@ -69,68 +212,264 @@ func (s settings) populateStruct(config any) error {
 		return &InvalidConfigTypeError{ProvidedType: config}
 	}
 
-	configValue := reflect.ValueOf(config).Elem()
+	if !s.failFast {
+		s.errs = &[]error{}
+	}
+
+	path := configStruct.Elem().Type().Name()
+
+	s.populating[nestedStructKey{configStruct.Elem().Type(), s.prefix}] = true
+
+	if err := s.populateFields(configStruct.Elem(), s.prefix, path); err != nil {
+		return err
+	}
+
+	if s.errorCount() == 0 {
+		if err := s.collect(buildStruct(configStruct.Elem(), s.prefix, path)); err != nil {
+			return err
+		}
+	}
+
+	if s.errorCount() == 0 {
+		if err := s.collect(validateStruct(configStruct.Elem(), path)); err != nil {
+			return err
+		}
+	}
+
+	keyErrs := s.collisionErrors()
+	if s.strict {
+		keyErrs = append(keyErrs, s.unknownKeys()...)
+	}
+
+	for _, err := range keyErrs {
+		if err := s.collect(err); err != nil {
+			return err
+		}
+	}
+
+	if s.errorCount() != 0 {
+		return &AggregateError{Errs: *s.errs}
+	}
+
+	return nil
+}
+
+// collect records err and returns nil, so that population continues with the next field, unless WithFailFast() is
+// used, in which case err is returned.
+func (s settings) collect(err error) error {
+	if err == nil || s.errs == nil {
+		return err
+	}
+
+	*s.errs = append(*s.errs, err)
+
+	return nil
+}
+
+// errorCount returns the number of errors collected so far.
+func (s settings) errorCount() int {
+	if s.errs == nil {
+		return 0
+	}
+
+	return len(*s.errs)
+}
 
+// populateFields populates the fields of a struct, where every key is prefixed with prefix, and path is the Go path
+// of the struct, e.g. Config.Server.
+//
+// It is used for both the config struct and nested structs, so that a field behaves the same at any depth.
+func (s settings) populateFields(configValue reflect.Value, prefix, path string) error {
 	for i := range configValue.NumField() {
 		field := configValue.Type().Field(i)
 		configFieldValue := configValue.Field(i)
 
-		// Ignore fields that are not exported.
-		if !configFieldValue.CanSet() {
+		// Ignore fields that are not exported, unless they are embedded structs with exported fields.
+		if !configFieldValue.CanSet() && !embeddedStruct(field) {
 			continue
 		}
 
-		jsonOptionValue, jsonOptionSet := field.Tag.Lookup(tagJSON)
-		if jsonOptionSet {
-			err := json.Unmarshal([]byte(s.source[jsonOptionValue]), configFieldValue.Addr().Interface())
-			if err != nil {
-				return fmt.Errorf("unmarshal JSON: %w", err)
-			}
-			continue
+		if err := s.collect(s.populateField(field, configFieldValue, prefix, fieldPath(path, field.Name))); err != nil {
+			return err
 		}
+	}
+
+	return s.collect(s.checkGroupTags(configValue, prefix, path))
+}
+
+// populateField populates a single field of a struct. Any error is annotated with the path of the field, and the
+// location of its value.
+func (s settings) populateField(
+	field reflect.StructField,
+	configFieldValue reflect.Value,
+	prefix, path string,
+) error {
+	d, err := parseFieldDescriptor(field, prefix, s.autoKeys)
+	if err != nil {
+		return fieldError("parse field tags", err, path, "")
+	}
+
+	d.path = path
+
+	if d.ignored {
+		return nil
+	}
 
-		if err := s.handlePrefixTag(field, configFieldValue, ""); err != nil {
-			return fmt.Errorf("handle prefix tag: %w", err)
+	jsonOptionValue, jsonOptionSet := field.Tag.Lookup(tagJSON)
+	if jsonOptionSet {
+		d.key = prefix + jsonOptionValue
+
+		value, origin, err := s.resolveValue(d.key, d)
+		if err != nil {
+			return err
 		}
 
-		key := field.Tag.Get(tagEnv)
-		if key == "" {
-			continue
+		if value != "" {
+			if err := json.Unmarshal([]byte(value), configFieldValue.Addr().Interface()); err != nil {
+				return fieldError("unmarshal JSON", err, path, origin.location)
+			}
 		}
 
-		value := s.source[key]
-		if value == "" {
-			if err := checkRequiredTag(key, field); err != nil {
-				return fmt.Errorf("check required tag: %w", err)
+		if err := validateField(d, configFieldValue, value != ""); err != nil {
+			return fieldError("validate field", err, path, origin.location)
+		}
+
+		s.record(d, configFieldValue, origin)
+
+		return nil
+	}
+
+	if err := s.handlePrefixTag(field, configFieldValue, prefix, path); err != nil {
+		return fieldError("handle prefix tag", err, path, "")
+	}
+
+	if d.key == "" || s.nestedField(field) {
+		return nil
+	}
+
+	value, origin, err := s.resolveValue(d.key, d)
+	if err != nil {
+		return err
+	}
+
+	if value != "" {
+		if err := s.setFieldValue(configFieldValue, d, entry{d.key, value}); err != nil {
+			if d.secret {
+				err = redactSecret(err, value)
 			}
 
-			value = field.Tag.Get(tagDefault)
+			return fieldError("set field value", err, path, origin.location)
 		}
 
-		value, err := s.resolveReplacement(value)
-		if err != nil {
-			return fmt.Errorf("resolve replacement: %w", err)
+	}
+
+	if err := checkSchemeTag(d.key, field, configFieldValue, value != ""); err != nil {
+		return fieldError("check scheme tag", err, path, origin.location)
+	}
+
+	if err := validateField(d, configFieldValue, value != ""); err != nil {
+		return fieldError("validate field", err, path, origin.location)
+	}
+
+	s.record(d, configFieldValue, origin)
+
+	return nil
+}
+
+// valueOrigin describes where the value of a field came from.
+type valueOrigin struct {
+	key      string // key is the key or alias the value was found under, if any.
+	source   string // source is the name of the source, e.g. env, or default for the default tag.
+	location string
+}
+
+// resolveValue returns the value for a field from settings.source, applying the required, default and fromfile tags,
+// and resolving any text replacement, along with the origin of the value.
+//
+// An empty value means the field is not set, and should be left untouched.
+func (s settings) resolveValue(key string, d fieldDescriptor) (string, valueOrigin, error) {
+	value, matchedKey := s.lookupAliases(key, d)
+
+	var origin valueOrigin
+	if value != "" {
+		origin = valueOrigin{
+			key:      matchedKey,
+			source:   s.origins[s.canonicalKey(matchedKey)],
+			location: s.location(matchedKey),
+		}
+	}
+
+	if err := s.checkSourcesTag(matchedKey, d); err != nil {
+		return "", origin, fieldError("check sources tag", err, d.path, origin.location)
+	}
+
+	if value == "" {
+		if err := s.checkRequiredTag(key, d); err != nil {
+			return "", origin, fieldError("check required tag", err, d.path, origin.location)
 		}
 
-		if err := s.setFieldValue(
-			configFieldValue, entry{key, value}); err != nil {
-			return fmt.Errorf("set field value: %w", err)
+		value = d.defaultValue
+		if value != "" {
+			origin = valueOrigin{source: locationDefault, location: locationDefault}
 		}
 	}
 
-	return nil
+	value, err := s.resolveReplacement(value)
+	if err != nil {
+		return "", origin, fieldError("resolve replacement", err, d.path, origin.location)
+	}
+
+	value, err = handleFromFileTag(d, value)
+	if err != nil {
+		return "", origin, fieldError("handle fromfile tag", err, d.path, origin.location)
+	}
+
+	return value, origin, nil
+}
+
+// lookupAliases returns the value of key from settings.source, falling back to the aliases of the field in order,
+// along with the key the value was found under. Usage of deprecated aliases is reported to the warning handler.
+func (s settings) lookupAliases(key string, d fieldDescriptor) (string, string) {
+	if value := s.lookup(key); value != "" {
+		return value, key
+	}
+
+	for _, alias := range d.aliases {
+		value := s.lookup(alias)
+		if value == "" {
+			continue
+		}
+
+		if d.deprecated && s.warn != nil {
+			s.warn(&DeprecatedKeyWarning{
+				Key:         alias,
+				Replacement: key,
+				FieldPath:   d.path,
+				Location:    s.location(alias),
+			})
+		}
+
+		return value, alias
+	}
+
+	return "", ""
 }
 
 // resolveReplacement checks if a string has the pattern of ${...}, and if so, uses values in settings.source to
 // replace the pattern, and returns the newly created string.
 func (s settings) resolveReplacement(value string) (string, error) {
-	match := textReplacementRegex.FindStringSubmatch(value)
+	match := textReplacementRegex.FindAllString(value, -1)
 
 	for _, m := range match {
 		environmentValue := strings.TrimPrefix(m, "${")
 		environmentValue = strings.TrimSuffix(environmentValue, "}")
 
-		replacementValue := s.source[environmentValue]
+		replacementValue := s.lookup(s.prefix + environmentValue)
+		if replacementValue == "" && s.prefix != "" {
+			// Allow replacement using values outside the prefix, e.g. ${HOME}.
+			replacementValue = s.lookup(environmentValue)
+		}
+
 		if replacementValue == "" {
 			return "", &ReplacementError{VariableName: environmentValue}
 		}
@@ -141,39 +480,412 @@ func (s settings) resolveReplacement(value string) (string, error) {
 	return value, nil
 }
 
-// populateNestedConfig populates a nested struct.
-func (s settings) populateNestedConfig(nestedConfig reflect.Value, prefix string) error {
-	for i := range nestedConfig.NumField() {
-		field := nestedConfig.Type().Field(i)
-		configFieldValue := nestedConfig.Field(i)
+// nestedStruct reports whether typ is populated as a nested config struct.
+func (s settings) nestedStruct(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && !s.decodable(typ)
+}
+
+// nestedKind reports whether field is an interface populated with a concrete type selected by a discriminator.
+func (s settings) nestedKind(field reflect.StructField) bool {
+	return field.Type.Kind() == reflect.Interface && field.Tag.Get(tagDiscriminator) != ""
+}
+
+// nestedCollection reports whether field is a slice or a map keyed by strings, of nested config structs.
+func (s settings) nestedCollection(field reflect.StructField) bool {
+	switch field.Type.Kind() { //nolint:exhaustive // Only slices and maps can be collections.
+	case reflect.Slice:
+		return s.nestedStruct(field.Type.Elem())
+	case reflect.Map:
+		return field.Type.Key().Kind() == reflect.String && s.nestedStruct(field.Type.Elem())
+	default:
+		return false
+	}
+}
+
+// nestedField reports whether field is populated using a prefix, rather than from a single key.
+func (s settings) nestedField(field reflect.StructField) bool {
+	if s.nestedCollection(field) {
+		_, prefixOptionSet := s.prefixTag(field)
+
+		return prefixOptionSet
+	}
+
+	return s.nestedStruct(field.Type) || s.nestedStructPointer(field.Type) || s.nestedKind(field)
+}
+
+// nestedStructPointer reports whether typ is a pointer to a nested config struct, which is allocated on demand.
+func (s settings) nestedStructPointer(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Pointer && s.nestedStruct(typ.Elem()) && !s.decodable(typ)
+}
+
+// fieldPath returns the Go path of a field within the struct at path.
+func fieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// embeddedStruct reports whether field is an anonymous struct, whose fields are flattened into the parent struct.
+func embeddedStruct(field reflect.StructField) bool {
+	return field.Anonymous && field.Type.Kind() == reflect.Struct
+}
+
+// populateNested populates a nested struct, builds any derived state, and validates it once populated without field
+// errors.
+func (s settings) populateNested(nestedConfig reflect.Value, prefix, path string) error {
+	errorCount := s.errorCount()
+
+	embedded := s.embedded
+	s.embedded = false
+
+	key := nestedStructKey{nestedConfig.Type(), prefix}
+	s.populating[key] = true
+
+	defer delete(s.populating, key)
+
+	if err := s.populateFields(nestedConfig, prefix, path); err != nil {
+		return fmt.Errorf("populate nested config struct: %w", err)
+	}
+
+	// Building and validating a struct with field errors would only report knock-on errors.
+	if s.errorCount() != errorCount || !nestedConfig.Addr().CanInterface() {
+		return nil
+	}
+
+	if err := buildStruct(nestedConfig, prefix, path); err != nil {
+		return err
+	}
+
+	// The Validate method of an embedded struct is promoted to the struct embedding it, which is validated instead.
+	if embedded {
+		return nil
+	}
+
+	return validateStruct(nestedConfig, path)
+}
+
+// buildStruct builds any derived state of a populated struct, such as the *tls.Config of TLS.
+func buildStruct(configValue reflect.Value, prefix, path string) error {
+	if b, ok := configValue.Addr().Interface().(builder); ok {
+		if err := b.build(prefix); err != nil {
+			return fieldError("build config struct", err, path, "")
+		}
+	}
+
+	return nil
+}
+
+// populateNestedPointer populates a pointer to a nested struct. The pointer is left nil unless settings.source
+// contains a key for the nested struct, in which case it is allocated and populated.
+func (s settings) populateNestedPointer(nestedPointer reflect.Value, prefix, path string) error {
+	// A pointer to an enclosing struct with the same prefix would be populated from the same keys forever.
+	if s.populating[nestedStructKey{nestedPointer.Type().Elem(), prefix}] {
+		return nil
+	}
+
+	if !s.nestedKeyPresent(nestedPointer.Type().Elem(), prefix) {
+		return nil
+	}
+
+	if nestedPointer.IsNil() {
+		nestedPointer.Set(reflect.New(nestedPointer.Type().Elem()))
+	}
+
+	return s.populateNested(nestedPointer.Elem(), prefix, path)
+}
+
+// populateNestedKind populates an interface field with the concrete config struct type registered using WithKinds()
+// for the value of the field's discriminator key. The field is left nil if the discriminator key is not set.
+func (s settings) populateNestedKind(
+	field reflect.StructField,
+	nestedInterface reflect.Value,
+	prefix, path string,
+) error {
+	discriminatorKey := prefix + field.Tag.Get(tagDiscriminator)
+
+	kind := s.lookup(discriminatorKey)
+	if kind == "" {
+		d, err := parseFieldDescriptor(field, prefix, s.autoKeys)
+		if err != nil {
+			return fieldError("parse field tags", err, path, "")
+		}
+
+		if err := s.checkRequiredTag(discriminatorKey, d); err != nil {
+			return fieldError("check required tag", err, path, "")
+		}
+
+		return nil
+	}
+
+	typ, ok := s.kinds[field.Type][kind]
+	if !ok {
+		return &UnknownKindError{
+			FieldName:  discriminatorKey,
+			Kind:       kind,
+			ValidKinds: slices.Sorted(maps.Keys(s.kinds[field.Type])),
+			Location:   s.location(discriminatorKey),
+		}
+	}
+
+	if !typ.AssignableTo(field.Type) || !s.nestedStruct(reflectTypeElem(typ)) {
+		return &UnsupportedFieldTypeError{FieldType: reflect.Zero(typ).Interface()}
+	}
+
+	nestedConfig := reflect.New(reflectTypeElem(typ))
+	if err := s.populateNested(nestedConfig.Elem(), prefix, path); err != nil {
+		return fmt.Errorf("populate kind %v: %w", kind, err)
+	}
+
+	if typ.Kind() == reflect.Pointer {
+		nestedInterface.Set(nestedConfig)
+	} else {
+		nestedInterface.Set(nestedConfig.Elem())
+	}
+
+	return nil
+}
+
+// nestedKeyPresent reports whether settings.source contains a key used to populate the nested struct type.
+func (s settings) nestedKeyPresent(typ reflect.Type, prefix string) bool {
+	if prefix != "" {
+		return len(s.keysWithPrefix(prefix)) != 0
+	}
+
+	// Without a prefix, only the keys of the nested struct itself can be checked.
+	for _, key := range s.structKeys(typ) {
+		if _, ok := s.source[s.canonicalKey(key)]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// populateNestedSlice populates a slice of nested structs, where each element uses the prefix followed by its index,
+// e.g. BACKEND_0_HOST and BACKEND_1_HOST.
+//
+// The number of elements is discovered from the keys in settings.source, and indices must be contiguous from 0. An
+// *IndexGapError is returned for keys which would otherwise be ignored, such as BACKEND_2_HOST without BACKEND_1_HOST,
+// or BACKEND_01_HOST.
+func (s settings) populateNestedSlice(nestedSlice reflect.Value, prefix, path string) error {
+	indices := map[int][]string{}
+
+	var invalidKeys []string
+
+	for _, rest := range s.keysWithPrefix(prefix) {
+		index, _, found := strings.Cut(rest, "_")
+		if !found || strings.Trim(index, "0123456789") != "" || index == "" {
+			continue
+		}
+
+		i, err := strconv.Atoi(index)
+		if err != nil || strconv.Itoa(i) != index {
+			invalidKeys = append(invalidKeys, s.canonicalKey(prefix)+rest)
 
-		if !configFieldValue.CanSet() || !configFieldValue.IsZero() {
 			continue
 		}
 
-		jsonOptionValue, jsonOptionSet := field.Tag.Lookup(tagJSON)
-		if jsonOptionSet {
-			err := json.Unmarshal([]byte(s.source[jsonOptionValue]), &configFieldValue)
-			if err != nil {
-				return fmt.Errorf("handle JSON tag: %w", err)
+		indices[i] = append(indices[i], s.canonicalKey(prefix)+rest)
+	}
+
+	length := 0
+	for indices[length] != nil {
+		length++
+	}
+
+	for i, keys := range indices {
+		if i > length {
+			invalidKeys = append(invalidKeys, keys...)
+		}
+	}
+
+	if len(invalidKeys) != 0 {
+		slices.Sort(invalidKeys)
+
+		return &IndexGapError{Prefix: prefix, MissingIndex: length, Keys: invalidKeys}
+	}
+
+	if length == 0 {
+		return nil
+	}
+
+	slice := reflect.MakeSlice(nestedSlice.Type(), length, length)
+
+	for i := range length {
+		elementPath := fmt.Sprintf("%v[%d]", path, i)
+		if err := s.populateNested(slice.Index(i), prefix+strconv.Itoa(i)+"_", elementPath); err != nil {
+			return fmt.Errorf("populate element %d: %w", i, err)
+		}
+	}
+
+	nestedSlice.Set(slice)
+
+	return nil
+}
+
+// populateNestedMap populates a map of nested structs, where each entry uses the prefix followed by its name, e.g.
+// DB_PRIMARY_HOST and DB_REPLICA_HOST populate the entries PRIMARY and REPLICA.
+//
+// Names are discovered from the keys in settings.source which end with a key of the nested struct.
+func (s settings) populateNestedMap(nestedMap reflect.Value, prefix, path string) error {
+	suffixes := s.structKeys(nestedMap.Type().Elem())
+	selfPrefixes := s.selfPrefixes(nestedMap.Type().Elem(), nestedMap.Type().Elem(), "", map[reflect.Type]bool{})
+
+	// Prefer the longest matching suffix, so that the shortest name is discovered.
+	slices.SortFunc(suffixes, func(a, b string) int { return len(b) - len(a) })
+
+	names := map[string]bool{}
+
+	for _, rest := range s.keysWithPrefix(prefix) {
+		for _, suffix := range suffixes {
+			name, found := strings.CutSuffix(rest, "_"+s.canonicalKey(suffix))
+			if found && name != "" {
+				names[s.trimSelfPrefixes(name, selfPrefixes)] = true
+
+				break
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	populatedMap := reflect.MakeMapWithSize(nestedMap.Type(), len(names))
+
+	for name := range names {
+		element := reflect.New(nestedMap.Type().Elem()).Elem()
+
+		entryPath := fmt.Sprintf("%v[%v]", path, name)
+		if err := s.populateNested(element, prefix+name+"_", entryPath); err != nil {
+			return fmt.Errorf("populate entry %v: %w", name, err)
+		}
+
+		populatedMap.SetMapIndex(reflect.ValueOf(name).Convert(nestedMap.Type().Key()), element)
+	}
+
+	nestedMap.Set(populatedMap)
+
+	return nil
+}
+
+// selfPrefixes returns the prefixes, relative to root, of the nested structs within typ which have the type of root,
+// such as the prefix of a pointer field to the struct's own type.
+func (s settings) selfPrefixes(root, typ reflect.Type, prefix string, visiting map[reflect.Type]bool) []string {
+	visiting[typ] = true
+	defer delete(visiting, typ)
+
+	var prefixes []string
+
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if (!field.IsExported() && !embeddedStruct(field)) ||
+			!(s.nestedStruct(field.Type) || s.nestedStructPointer(field.Type)) {
+			continue
+		}
+
+		fieldPrefix, _ := s.prefixTag(field)
+		fieldPrefix = prefix + fieldPrefix
+
+		switch elem := reflectTypeElem(field.Type); {
+		case elem == root:
+			prefixes = append(prefixes, fieldPrefix)
+		case !visiting[elem]:
+			prefixes = append(prefixes, s.selfPrefixes(root, elem, fieldPrefix, visiting)...)
+		}
+	}
+
+	return prefixes
+}
+
+// trimSelfPrefixes trims the prefixes returned by selfPrefixes from the end of a map entry name, so that the keys of a
+// struct nested within an entry, with the type of the entry, are not discovered as a separate entry.
+func (s settings) trimSelfPrefixes(name string, selfPrefixes []string) string {
+	for trimmed := true; trimmed; {
+		trimmed = false
+
+		for _, prefix := range selfPrefixes {
+			rest, found := strings.CutSuffix(name, "_"+s.canonicalKey(strings.TrimSuffix(prefix, "_")))
+			if found && rest != "" && prefix != "" {
+				name, trimmed = rest, true
 			}
+		}
+	}
 
+	return name
+}
+
+// structKeys returns the keys, relative to the struct, used to populate a nested struct type.
+//
+// Keys of slices and maps of nested structs cannot be known ahead of time, and are not included.
+func (s settings) structKeys(typ reflect.Type) []string {
+	return s.structKeysVisiting(typ, map[reflect.Type]bool{})
+}
+
+// structKeysVisiting returns the keys of structKeys, skipping nested structs whose type is already being visited, so
+// that self-referential types, such as a struct with a pointer to its own type, do not recurse forever.
+func (s settings) structKeysVisiting(typ reflect.Type, visiting map[reflect.Type]bool) []string {
+	visiting[typ] = true
+	defer delete(visiting, typ)
+
+	var keys []string
+
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() && !embeddedStruct(field) {
 			continue
 		}
 
-		if err := s.handlePrefixTag(field, configFieldValue, prefix); err != nil {
-			return fmt.Errorf("handle prefix tag: %w", err)
+		if jsonOptionValue, jsonOptionSet := field.Tag.Lookup(tagJSON); jsonOptionSet {
+			keys = append(keys, jsonOptionValue)
+
+			continue
 		}
 
-		environmentVariableKey := prefix + field.Tag.Get(tagEnv)
-		if environmentVariableKey == prefix { // Ensure tag is set.
+		key, _, _ := strings.Cut(field.Tag.Get(tagEnv), ",")
+		if key == "-" {
 			continue
 		}
-		if err := s.setFieldValue(
-			configFieldValue, entry{environmentVariableKey, s.source[environmentVariableKey]}); err != nil {
-			return fmt.Errorf("set field value: %w", err)
+
+		if s.nestedStruct(field.Type) || s.nestedStructPointer(field.Type) {
+			if visiting[reflectTypeElem(field.Type)] {
+				continue
+			}
+
+			prefix, _ := s.prefixTag(field)
+
+			for _, key := range s.structKeysVisiting(reflectTypeElem(field.Type), visiting) {
+				keys = append(keys, prefix+key)
+			}
+
+			continue
+		}
+
+		if key == "" && s.autoKeys != nil && !s.nestedField(field) {
+			key = s.autoKeys(field.Name)
+		}
+
+		if key != "" {
+			keys = append(keys, key)
+		}
+
+		if aliases := field.Tag.Get(tagAliases); aliases != "" {
+			for _, alias := range strings.Split(aliases, ",") {
+				keys = append(keys, strings.TrimSpace(alias))
+			}
 		}
 	}
 
-	return nil
+	return keys
+}
+
+// reflectTypeElem returns the element type of pointer types, otherwise typ itself.
+func reflectTypeElem(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Pointer {
+		return typ.Elem()
+	}
+
+	return typ
 }
@@ -2,8 +2,10 @@ package envconfig_test
 
 import (
 	"bufio"
+	"errors"
 	"log"
 	"os"
+	"reflect"
 	"slices"
 	"strings"
 	"testing"
@@ -401,3 +403,464 @@ func TestSetSuccessWithJsonField(t *testing.T) {
 		t.Errorf("got %+v, want %+v", config, want)
 	}
 }
+
+func TestSetSuccessWithNestedStructSlice(t *testing.T) {
+	type Tag struct {
+		Name string `env:"NAME"`
+	}
+
+	type Backend struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+		Tags []Tag  `prefix:"TAGS_"`
+	}
+
+	type Config struct {
+		Backends []Backend `prefix:"BACKEND_"`
+	}
+
+	var config Config
+
+	want := Config{
+		Backends: []Backend{
+			{Host: "alpha.internal", Port: 8080},
+			{Host: "beta.internal", Port: 9090, Tags: []Tag{{Name: "canary"}}},
+		},
+	}
+
+	if err := envconfig.Set(&config, envconfig.WithFilepath("./test_data/success_with_nested_struct_slice.env")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("got %+v, want %+v", config, want)
+	}
+}
+
+type DBPoolConfig struct {
+	MaxConns int `env:"MAX_CONNS" default:"10"`
+}
+
+type DBConfig struct {
+	Host string       `env:"HOST" required:"true"`
+	Port int          `env:"PORT" default:"5432"`
+	Pool DBPoolConfig `prefix:"POOL_"`
+}
+
+func TestSetFailureWithNestedStructSliceIndexGap(t *testing.T) {
+	type Backend struct {
+		Host string `env:"HOST"`
+	}
+
+	type Config struct {
+		Backends []Backend `prefix:"GAP_BE_"`
+	}
+
+	testCases := map[string]struct {
+		env      map[string]string
+		wantKeys []string
+	}{
+		"missing index": {
+			env:      map[string]string{"GAP_BE_0_HOST": "alpha", "GAP_BE_2_HOST": "gamma"},
+			wantKeys: []string{"GAP_BE_2_HOST"},
+		},
+		"leading zero": {
+			env:      map[string]string{"GAP_BE_0_HOST": "alpha", "GAP_BE_01_HOST": "beta"},
+			wantKeys: []string{"GAP_BE_01_HOST"},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			var config Config
+
+			var gapErr *envconfig.IndexGapError
+			if err := envconfig.Set(&config); !errors.As(err, &gapErr) {
+				t.Fatalf("got %v, want IndexGapError", err)
+			}
+
+			if gapErr.MissingIndex != 1 || !reflect.DeepEqual(gapErr.Keys, tc.wantKeys) {
+				t.Errorf("got missing index %d and keys %v, want 1 and %v", gapErr.MissingIndex, gapErr.Keys, tc.wantKeys)
+			}
+		})
+	}
+}
+
+func TestSetSuccessWithNestedStructMap(t *testing.T) {
+	type Config struct {
+		Databases map[string]DBConfig `prefix:"DB_"`
+	}
+
+	var config Config
+
+	want := Config{
+		Databases: map[string]DBConfig{
+			"PRIMARY": {Host: "primary.internal", Port: 5432, Pool: DBPoolConfig{MaxConns: 10}},
+			"REPLICA": {Host: "replica.internal", Port: 5433, Pool: DBPoolConfig{MaxConns: 20}},
+		},
+	}
+
+	if err := envconfig.Set(&config, envconfig.WithFilepath("./test_data/success_with_nested_struct_map.env")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("got %+v, want %+v", config, want)
+	}
+}
+
+func TestSetFailureWithNestedStructMapMissingRequiredField(t *testing.T) {
+	type Config struct {
+		Databases map[string]DBConfig `prefix:"DB_"`
+	}
+
+	var config Config
+
+	err := envconfig.Set(&config, envconfig.WithFilepath("./test_data/failure_with_nested_struct_map_required.env"))
+
+	var requiredErr *envconfig.RequiredFieldError
+	if !errors.As(err, &requiredErr) {
+		t.Fatalf("got %v, want RequiredFieldError", err)
+	}
+
+	if requiredErr.FieldName != "DB_ANALYTICS_HOST" {
+		t.Errorf("got %v, want DB_ANALYTICS_HOST", requiredErr.FieldName)
+	}
+}
+
+type CommonConfig struct {
+	Service string `env:"SERVICE"`
+	logging
+}
+
+type logging struct {
+	LogLevel string `env:"LOG_LEVEL"`
+}
+
+type TraceConfig struct {
+	Enabled bool `env:"ENABLED"`
+}
+
+func TestSetSuccessWithEmbeddedStruct(t *testing.T) {
+	type HTTPConfig struct {
+		Port        int `env:"PORT"`
+		TraceConfig `prefix:"TRACE_"`
+	}
+
+	type Config struct {
+		CommonConfig
+		HTTP HTTPConfig `prefix:"HTTP_"`
+	}
+
+	var config Config
+
+	var want Config
+	want.Service = "orders"
+	want.LogLevel = "debug"
+	want.HTTP.Port = 8080
+	want.HTTP.Enabled = true
+
+	if err := envconfig.Set(&config, envconfig.WithFilepath("./test_data/success_with_embedded_struct.env")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if config != want {
+		t.Errorf("got %+v, want %+v", config, want)
+	}
+}
+
+type RedisConfig struct {
+	Addr string `env:"ADDR" required:"true"`
+	DB   int    `env:"DB" default:"0"`
+}
+
+func TestSetSuccessWithNestedStructPointer(t *testing.T) {
+	type Config struct {
+		Redis *RedisConfig `prefix:"REDIS_"`
+		Cache *RedisConfig `prefix:"CACHE_"`
+	}
+
+	var config Config
+
+	if err := envconfig.Set(&config, envconfig.WithFilepath("./test_data/success_with_nested_struct_pointer.env")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := (RedisConfig{Addr: "localhost:6379"}); config.Redis == nil || *config.Redis != want {
+		t.Errorf("got %+v, want %+v", config.Redis, want)
+	}
+
+	if config.Cache != nil {
+		t.Errorf("got %+v, want nil", config.Cache)
+	}
+}
+
+func TestSetFailureWithNestedStructPointerMissingRequiredField(t *testing.T) {
+	type Config struct {
+		Redis *RedisConfig `prefix:"REDIS_"`
+	}
+
+	var config Config
+
+	err := envconfig.Set(&config, envconfig.WithFilepath("./test_data/failure_with_nested_struct_pointer_required.env"))
+
+	var requiredErr *envconfig.RequiredFieldError
+	if !errors.As(err, &requiredErr) || requiredErr.FieldName != "REDIS_ADDR" {
+		t.Errorf("got %v, want RequiredFieldError for REDIS_ADDR", err)
+	}
+}
+
+type ReplicatedDB struct {
+	Host    string        `env:"HOST"`
+	Replica *ReplicatedDB `prefix:"REPLICA_"`
+}
+
+type ChainedNode struct {
+	Name string `env:"CYCLE_NODE_NAME"`
+	*ChainedNode
+}
+
+func TestSetSuccessWithSelfReferentialNestedStruct(t *testing.T) {
+	t.Run("map of structs with a pointer to their own type", func(t *testing.T) {
+		type Config struct {
+			Databases map[string]ReplicatedDB `prefix:"CYCLE_DB_"`
+		}
+
+		t.Setenv("CYCLE_DB_PRIMARY_HOST", "primary.internal")
+		t.Setenv("CYCLE_DB_PRIMARY_REPLICA_HOST", "replica.internal")
+		t.Setenv("CYCLE_DB_PRIMARY_REPLICA_REPLICA_HOST", "backup.internal")
+
+		var config Config
+
+		if err := envconfig.Set(&config); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := Config{
+			Databases: map[string]ReplicatedDB{
+				"PRIMARY": {
+					Host: "primary.internal",
+					Replica: &ReplicatedDB{
+						Host:    "replica.internal",
+						Replica: &ReplicatedDB{Host: "backup.internal"},
+					},
+				},
+			},
+		}
+
+		if !reflect.DeepEqual(config, want) {
+			t.Errorf("got %+v, want %+v", config, want)
+		}
+	})
+
+	t.Run("embedded pointer to its own type", func(t *testing.T) {
+		t.Setenv("CYCLE_NODE_NAME", "root")
+
+		var config ChainedNode
+
+		if err := envconfig.Set(&config); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if config.Name != "root" || config.ChainedNode != nil {
+			t.Errorf("got %+v, want name root and nil embedded pointer", config)
+		}
+	})
+}
+
+type Storage interface {
+	Name() string
+}
+
+type S3Config struct {
+	Bucket string `env:"BUCKET" required:"true"`
+	Region string `env:"REGION"`
+}
+
+func (S3Config) Name() string { return "s3" }
+
+type LocalConfig struct {
+	Dir string `env:"DIR" default:"/tmp"`
+}
+
+func (*LocalConfig) Name() string { return "local" }
+
+func TestSetWithDiscriminator(t *testing.T) {
+	type Config struct {
+		Storage Storage `prefix:"STORAGE_" discriminator:"KIND"`
+	}
+
+	kinds := envconfig.WithKinds(reflect.TypeFor[Storage](), map[string]reflect.Type{
+		"s3":    reflect.TypeFor[S3Config](),
+		"local": reflect.TypeFor[*LocalConfig](),
+	})
+
+	t.Run("success with registered kind", func(t *testing.T) {
+		var config Config
+
+		if err := envconfig.Set(&config, kinds, envconfig.WithFilepath("./test_data/success_with_discriminator.env")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if want := (S3Config{Bucket: "uploads", Region: "eu-west-2"}); config.Storage != want {
+			t.Errorf("got %+v, want %+v", config.Storage, want)
+		}
+	})
+
+	t.Run("success with pointer kind", func(t *testing.T) {
+		t.Setenv("STORAGE_KIND", "local")
+
+		var config Config
+
+		if err := envconfig.Set(&config, kinds); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if local, ok := config.Storage.(*LocalConfig); !ok || local.Dir != "/tmp" {
+			t.Errorf("got %+v, want &{Dir:/tmp}", config.Storage)
+		}
+	})
+
+	t.Run("failure with unknown kind", func(t *testing.T) {
+		t.Setenv("STORAGE_KIND", "gcs")
+
+		var config Config
+
+		err := envconfig.Set(&config, kinds)
+
+		var kindErr *envconfig.UnknownKindError
+		if !errors.As(err, &kindErr) {
+			t.Fatalf("got %v, want UnknownKindError", err)
+		}
+
+		if !slices.Equal(kindErr.ValidKinds, []string{"local", "s3"}) {
+			t.Errorf("got %v, want [local s3]", kindErr.ValidKinds)
+		}
+	})
+}
+
+func TestSetSuccessWithNestedStructSemantics(t *testing.T) {
+	type Server struct {
+		URL     string `env:"URL"`
+		Port    int    `env:"PORT" required:"true"`
+		Timeout string `env:"TIMEOUT" default:"5s"`
+		Retries int    `env:"RETRIES"`
+		Name    string `env:"NAME"`
+		Limits  struct {
+			Burst int `json:"burst"`
+		} `envjson:"LIMITS"`
+	}
+
+	type Config struct {
+		Server Server `prefix:"SEMANTICS_"`
+	}
+
+	var config Config
+	config.Server.Name = "preset"
+	config.Server.Retries = 3
+
+	var want Config
+	want.Server.URL = "https://example.com:8443"
+	want.Server.Port = 8443
+	want.Server.Timeout = "5s"
+	want.Server.Retries = 3
+	want.Server.Name = "from-env"
+	want.Server.Limits.Burst = 5
+
+	if err := envconfig.Set(&config, envconfig.WithFilepath("./test_data/success_with_nested_struct_semantics.env")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if config != want {
+		t.Errorf("got %+v, want %+v", config, want)
+	}
+}
+
+func TestSetFailureWithNestedStructMissingRequiredField(t *testing.T) {
+	type Config struct {
+		Server struct {
+			Database struct {
+				Password string `env:"PASSWORD" required:"true"`
+			} `prefix:"DATABASE_"`
+		} `prefix:"NESTED_REQUIRED_"`
+	}
+
+	var config Config
+
+	var requiredErr *envconfig.RequiredFieldError
+	if err := envconfig.Set(&config); !errors.As(err, &requiredErr) || requiredErr.FieldName != "NESTED_REQUIRED_DATABASE_PASSWORD" {
+		t.Errorf("got %v, want RequiredFieldError for NESTED_REQUIRED_DATABASE_PASSWORD", err)
+	}
+}
+
+func TestSetFailureWithErrorLocation(t *testing.T) {
+	type Config struct {
+		Server struct {
+			Host string `env:"HOST"`
+			Port int    `env:"PORT"`
+		} `prefix:"LOCATED_SERVER_"`
+		Timeout time.Duration `env:"LOCATED_TIMEOUT"`
+		Name    string        `env:"LOCATED_NAME,required"`
+	}
+
+	t.Setenv("LOCATED_TIMEOUT", "soon")
+
+	var config Config
+
+	err := envconfig.Set(&config, envconfig.WithFilepath("./test_data/failure_with_error_location.env"))
+
+	var aggregateErr *envconfig.AggregateError
+	if !errors.As(err, &aggregateErr) || len(aggregateErr.Errs) != 3 {
+		t.Fatalf("got %v, want 3 errors", err)
+	}
+
+	var portErr, timeoutErr *envconfig.FieldConversionError
+	if !errors.As(aggregateErr.Errs[0], &portErr) || !errors.As(aggregateErr.Errs[1], &timeoutErr) {
+		t.Fatalf("got %v, want FieldConversionError for port and timeout", err)
+	}
+
+	if portErr.FieldPath != "Config.Server.Port" ||
+		portErr.Location != "./test_data/failure_with_error_location.env:3" {
+		t.Errorf("got field path %q and location %q for port", portErr.FieldPath, portErr.Location)
+	}
+
+	if timeoutErr.FieldPath != "Config.Timeout" || timeoutErr.Location != "env" {
+		t.Errorf("got field path %q and location %q for timeout", timeoutErr.FieldPath, timeoutErr.Location)
+	}
+
+	var requiredErr *envconfig.RequiredFieldError
+	if !errors.As(aggregateErr.Errs[2], &requiredErr) || requiredErr.FieldPath != "Config.Name" {
+		t.Errorf("got %v, want RequiredFieldError for Config.Name", aggregateErr.Errs[2])
+	}
+
+	for _, want := range []string{
+		"(field Config.Server.Port from ./test_data/failure_with_error_location.env:3)",
+		"(field Config.Timeout from env)",
+		"(field Config.Name)",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("got message %q, want it to contain %q", err.Error(), want)
+		}
+	}
+}
+
+func TestSetFailureWithParseErrorLocation(t *testing.T) {
+	var config struct {
+		Valid string `env:"LOCATED_VALID"`
+	}
+
+	err := envconfig.Set(&config, envconfig.WithFilepath("./test_data/failure_with_parse_error_location.env"))
+
+	var parseErr *envconfig.ParseError
+	if !errors.As(err, &parseErr) || !errors.Is(err, envconfig.ErrSyntax) {
+		t.Fatalf("got %v, want ParseError", err)
+	}
+
+	if want := "./test_data/failure_with_parse_error_location.env:2"; parseErr.Location != want {
+		t.Errorf("got location %q, want %q", parseErr.Location, want)
+	}
+}
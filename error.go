@@ -3,6 +3,8 @@ package envconfig
 import (
 	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 )
 
 // FileTypeValidationError occurs when the .env config file fails to open.
@@ -46,11 +48,19 @@ type FieldConversionError struct {
 	FieldName  string
 	TargetType string
 	Err        error
+	FieldPath  string
+	Location   string
 }
 
 // Error satisfies the error interface for FieldConversionError.
 func (e *FieldConversionError) Error() string {
-	return fmt.Sprintf("failed to convert field %v to %v: %v", e.FieldName, e.TargetType, e.Err.Error())
+	return fmt.Sprintf("failed to convert field %v to %v%v: %v", e.FieldName, e.TargetType,
+		errorContext(e.FieldPath, e.Location), e.Err.Error())
+}
+
+func (e *FieldConversionError) annotate(path, location string) {
+	setIfEmpty(&e.FieldPath, path)
+	setIfEmpty(&e.Location, location)
 }
 
 // Unwrap allows FieldConversionError to be used with errors.Is and errors.As.
@@ -59,11 +69,18 @@ func (e *FieldConversionError) Unwrap() error { return e.Err }
 // UnsupportedFieldTypeError occurs when the a field type on the config struct is not compatible.
 type UnsupportedFieldTypeError struct {
 	FieldType any
+	FieldPath string
+	Location  string
 }
 
 // Error satisfies the error interface for UnsupportedFieldTypeError.
 func (e *UnsupportedFieldTypeError) Error() string {
-	return fmt.Sprintf("unsupported field type: %T", e.FieldType)
+	return fmt.Sprintf("unsupported field type: %T%v", e.FieldType, errorContext(e.FieldPath, e.Location))
+}
+
+func (e *UnsupportedFieldTypeError) annotate(path, location string) {
+	setIfEmpty(&e.FieldPath, path)
+	setIfEmpty(&e.Location, location)
 }
 
 // InvalidConfigTypeError occurs when config is not a pointer to a struct.
@@ -79,11 +96,47 @@ func (e *InvalidConfigTypeError) Error() string {
 // RequiredFieldError occurs when a required field is not set and in the environment variables.
 type RequiredFieldError struct {
 	FieldName string
+	Condition string // Condition is the required_if or required_with rule which required the field, if any.
+	FieldPath string
+	Location  string // Location is where the key which triggered the Condition came from, if any.
 }
 
 // Error satisfies the error interface for RequiredFieldError.
 func (e *RequiredFieldError) Error() string {
-	return fmt.Sprintf("required field is not set in environment variables: %v", e.FieldName)
+	context := errorContext(e.FieldPath, e.Location)
+
+	if e.Condition != "" {
+		return fmt.Sprintf("required field is not set in environment variables: %v (%v)%v", e.FieldName, e.Condition,
+			context)
+	}
+
+	return fmt.Sprintf("required field is not set in environment variables: %v%v", e.FieldName, context)
+}
+
+func (e *RequiredFieldError) annotate(path, location string) {
+	setIfEmpty(&e.FieldPath, path)
+	setIfEmpty(&e.Location, location)
+}
+
+// GroupError occurs when the fields sharing a group tag do not satisfy the rule of the group, e.g. exactly_one.
+type GroupError struct {
+	Group      string
+	Rule       string
+	Keys       []string
+	SetKeys    []string
+	StructPath string
+	Locations  []string // Locations holds where each of SetKeys came from.
+}
+
+// Error satisfies the error interface for GroupError.
+func (e *GroupError) Error() string {
+	setKeys := "none"
+	if len(e.SetKeys) != 0 {
+		setKeys = strings.Join(e.SetKeys, ", ")
+	}
+
+	return fmt.Sprintf("group %v requires %v of %v, got %v%v", e.Group, strings.ReplaceAll(e.Rule, "_", " "),
+		strings.Join(e.Keys, ", "), setKeys, errorContext(e.StructPath, strings.Join(e.Locations, ", ")))
 }
 
 // InvalidOptionConversionError occurs when an option is invalid for a field.
@@ -91,11 +144,19 @@ type InvalidOptionConversionError struct {
 	FieldName string
 	Option    string
 	Err       error
+	FieldPath string
+	Location  string
 }
 
 // Error satisfies the error interface for InvalidOptionConversionError.
 func (e *InvalidOptionConversionError) Error() string {
-	return fmt.Sprintf("invalid option %v conversion for field %v: %v", e.Option, e.FieldName, e.Err.Error())
+	return fmt.Sprintf("invalid option %v conversion for field %v%v: %v", e.Option, e.FieldName,
+		errorContext(e.FieldPath, e.Location), e.Err.Error())
+}
+
+func (e *InvalidOptionConversionError) annotate(path, location string) {
+	setIfEmpty(&e.FieldPath, path)
+	setIfEmpty(&e.Location, location)
 }
 
 // Unwrap allows InvalidOptionConversionError to be used with errors.Is and errors.As.
@@ -104,46 +165,281 @@ func (e *InvalidOptionConversionError) Unwrap() error { return e.Err }
 // PrefixOptionError occurs when the prefix tag is invalid or not set on a nested struct.
 type PrefixOptionError struct {
 	FieldName any
+	FieldPath string
 }
 
 // Error satisfies the error interface for PrefixOptionError.
 func (e *PrefixOptionError) Error() string {
-	return fmt.Sprintf("prefix option is not set for nested struct field: %v", e.FieldName)
+	return fmt.Sprintf("prefix option is not set for nested struct field: %v%v", e.FieldName,
+		errorContext(e.FieldPath, ""))
+}
+
+func (e *PrefixOptionError) annotate(path, _ string) {
+	setIfEmpty(&e.FieldPath, path)
+}
+
+// IndexGapError occurs when the indices of the keys for a slice of nested structs are not contiguous from 0, or are
+// not canonical, e.g. BACKEND_01_HOST, so that the keys would otherwise be ignored.
+type IndexGapError struct {
+	Prefix       string
+	MissingIndex int
+	Keys         []string // Keys holds the keys which cannot be used.
+	FieldPath    string
+}
+
+// Error satisfies the error interface for IndexGapError.
+func (e *IndexGapError) Error() string {
+	return fmt.Sprintf("indices for %v must be contiguous from 0, missing index %d, cannot use keys %v%v", e.Prefix,
+		e.MissingIndex, strings.Join(e.Keys, ", "), errorContext(e.FieldPath, ""))
+}
+
+func (e *IndexGapError) annotate(path, _ string) {
+	setIfEmpty(&e.FieldPath, path)
+}
+
+// UnknownKindError occurs when the discriminator key of an interface field does not match a kind registered using
+// WithKinds().
+type UnknownKindError struct {
+	FieldName  string
+	Kind       string
+	ValidKinds []string
+	FieldPath  string
+	Location   string
+}
+
+// Error satisfies the error interface for UnknownKindError.
+func (e *UnknownKindError) Error() string {
+	return fmt.Sprintf("unknown kind %q for %v%v, valid kinds are: %v", e.Kind, e.FieldName,
+		errorContext(e.FieldPath, e.Location), strings.Join(e.ValidKinds, ", "))
+}
+
+func (e *UnknownKindError) annotate(path, location string) {
+	setIfEmpty(&e.FieldPath, path)
+	setIfEmpty(&e.Location, location)
+}
+
+// DeprecatedKeyWarning is reported to the handler provided using WithWarningHandler() when a field is populated
+// using a deprecated alias.
+type DeprecatedKeyWarning struct {
+	Key         string
+	Replacement string
+	FieldPath   string
+	Location    string
+}
+
+// Error satisfies the error interface for DeprecatedKeyWarning.
+func (e *DeprecatedKeyWarning) Error() string {
+	return fmt.Sprintf("key %v is deprecated, use %v instead%v", e.Key, e.Replacement,
+		errorContext(e.FieldPath, e.Location))
+}
+
+// KeyCollisionError occurs when WithKeyNormalization() is used, and a source provides two distinct keys with
+// different values which normalise to the same key.
+type KeyCollisionError struct {
+	Key       string
+	RawKeys   []string
+	Locations []string // Locations holds where each of RawKeys came from.
+}
+
+// Error satisfies the error interface for KeyCollisionError.
+func (e *KeyCollisionError) Error() string {
+	return fmt.Sprintf("keys %v normalise to the same key: %v%v", strings.Join(e.RawKeys, ", "), e.Key,
+		errorContext("", strings.Join(e.Locations, ", ")))
+}
+
+// UnknownKeyError occurs when WithStrict() is used, and a key is not consumed by any field of the config struct.
+type UnknownKeyError struct {
+	Key        string
+	Location   string
+	Suggestion string // Suggestion is the closest known key, if any.
+}
+
+// Error satisfies the error interface for UnknownKeyError.
+func (e *UnknownKeyError) Error() string {
+	if e.Suggestion != "" {
+		return fmt.Sprintf("unknown key %v%v, did you mean %v?", e.Key, errorContext("", e.Location), e.Suggestion)
+	}
+
+	return fmt.Sprintf("unknown key %v%v", e.Key, errorContext("", e.Location))
+}
+
+// SourceNotAllowedError occurs when a key is provided by a source which is not listed in the sources tag of the field,
+// e.g. a secret provided as a flag.
+type SourceNotAllowedError struct {
+	Key            string
+	Source         string
+	AllowedSources []string
+	FieldPath      string
+	Location       string
+}
+
+// Error satisfies the error interface for SourceNotAllowedError.
+func (e *SourceNotAllowedError) Error() string {
+	return fmt.Sprintf("key %v must not be set by %v, allowed sources are: %v%v", e.Key, e.Source,
+		strings.Join(e.AllowedSources, ", "), errorContext(e.FieldPath, e.Location))
+}
+
+func (e *SourceNotAllowedError) annotate(path, location string) {
+	setIfEmpty(&e.FieldPath, path)
+	setIfEmpty(&e.Location, location)
+}
+
+// ValidationError occurs when a populated field does not satisfy a validation tag, such as min or oneof.
+type ValidationError struct {
+	FieldPath string
+	Key       string
+	Rule      string
+	Value     string // Value is redacted for fields marked as secret.
+	Location  string
+}
+
+// Error satisfies the error interface for ValidationError.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("field %v (%v) failed validation %v: %q%v", e.FieldPath, e.Key, e.Rule, e.Value,
+		errorContext("", e.Location))
 }
 
+func (e *ValidationError) annotate(path, location string) {
+	setIfEmpty(&e.FieldPath, path)
+	setIfEmpty(&e.Location, location)
+}
+
+// AggregateError occurs when one or more fields fail to be populated, and holds every error encountered, so that all
+// problems with the config are reported at once. errors.Is and errors.As match each of the errors.
+type AggregateError struct {
+	Errs []error
+}
+
+// Error satisfies the error interface for AggregateError.
+func (e *AggregateError) Error() string {
+	messages := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		messages[i] = err.Error()
+	}
+
+	return fmt.Sprintf("%d config errors: %v", len(e.Errs), strings.Join(messages, "; "))
+}
+
+// Unwrap allows AggregateError to be used with errors.Is and errors.As, in the same way as errors.Join.
+func (e *AggregateError) Unwrap() []error { return e.Errs }
+
+// StructValidationError occurs when the Validate method of a config struct or nested struct returns an error.
+type StructValidationError struct {
+	StructPath string
+	Err        error
+}
+
+// Error satisfies the error interface for StructValidationError.
+func (e *StructValidationError) Error() string {
+	return fmt.Sprintf("struct %v failed validation: %v", e.StructPath, e.Err)
+}
+
+// Unwrap allows StructValidationError to be used with errors.Is and errors.As.
+func (e *StructValidationError) Unwrap() error { return e.Err }
+
 // ReplacementError occurs when the environment variable being used for replacement is not set.
 type ReplacementError struct {
 	VariableName string
+	FieldPath    string
+	Location     string // Location is where the value containing the replacement came from.
 }
 
 // Error satisfies the error interface for ReplacementError.
 func (e *ReplacementError) Error() string {
-	return fmt.Sprintf("environment variable for replacement is not set: %v", e.VariableName)
+	return fmt.Sprintf("environment variable for replacement is not set: %v%v", e.VariableName,
+		errorContext(e.FieldPath, e.Location))
+}
+
+func (e *ReplacementError) annotate(path, location string) {
+	setIfEmpty(&e.FieldPath, path)
+	setIfEmpty(&e.Location, location)
 }
 
 // ParseError occurs when a line from the .env config file has been parsed incorrectly.
 type ParseError struct {
-	Line string
-	Err  error
+	Line     string
+	Err      error
+	Location string // Location is the file and line number, e.g. config/prod.env:14.
 }
 
 // ErrSyntax indicates that a line is invalid syntax.
 var ErrSyntax = errors.New("invalid syntax")
 
+// ErrMissingScheme indicates that a URL value does not contain a scheme.
+var ErrMissingScheme = errors.New("missing scheme")
+
+// ErrSchemeNotAllowed indicates that a URL value uses a scheme not listed in the scheme tag.
+var ErrSchemeNotAllowed = errors.New("scheme not allowed")
+
+// ErrInvalidByteSize indicates that a value is not a valid byte size.
+var ErrInvalidByteSize = errors.New("invalid byte size")
+
+// ErrInvalidDuration indicates that a value is not a valid duration.
+var ErrInvalidDuration = errors.New("invalid duration")
+
+// ErrUnknownEncoding indicates that the encoding tag contains an unsupported encoding.
+var ErrUnknownEncoding = errors.New("unknown encoding")
+
+// ErrNoCertificates indicates that a CA bundle does not contain any PEM encoded certificates.
+var ErrNoCertificates = errors.New("no certificates found")
+
+// ErrUnknownCipherSuite indicates that a cipher suite name is not supported by crypto/tls.
+var ErrUnknownCipherSuite = errors.New("unknown cipher suite")
+
+// ErrInsecureCipherSuite indicates that a cipher suite is insecure, and insecure cipher suites are not allowed.
+var ErrInsecureCipherSuite = errors.New("insecure cipher suite")
+
+// ErrUnknownTLSVersion indicates that a TLS version is not supported.
+var ErrUnknownTLSVersion = errors.New("unknown TLS version")
+
+// ErrInvalidTLSVersionRange indicates that the minimum TLS version is greater than the maximum TLS version.
+var ErrInvalidTLSVersionRange = errors.New("minimum TLS version is greater than maximum TLS version")
+
+// ErrUnknownClientAuth indicates that a TLS client authentication policy is not supported.
+var ErrUnknownClientAuth = errors.New("unknown client auth policy")
+
+// ErrUnknownOption indicates that the env tag contains an unsupported option.
+var ErrUnknownOption = errors.New("unknown option")
+
+// ErrInvalidCondition indicates that a required_if condition is not in the form KEY=VALUE.
+var ErrInvalidCondition = errors.New("invalid condition, expected KEY=VALUE")
+
+// ErrUnknownGroupRule indicates that the rule of a group tag is not supported.
+var ErrUnknownGroupRule = errors.New("unknown group rule")
+
+// ErrUnknownSource indicates that the sources tag contains an unsupported source.
+var ErrUnknownSource = errors.New("unknown source")
+
+// ErrEmptySeparator indicates that the sep option in the env tag is empty.
+var ErrEmptySeparator = errors.New("empty separator")
+
+// ErrUnsupportedRule indicates that a validation tag is not supported for the type of a field.
+var ErrUnsupportedRule = errors.New("validation rule not supported for field type")
+
 // Error statisfies the error interface for ParseError.
 func (e *ParseError) Error() string {
-	return fmt.Sprintf("parse line: %v: %v", e.Line, e.Err.Error())
+	return fmt.Sprintf("parse line: %v%v: %v", e.Line, errorContext("", e.Location), e.Err.Error())
 }
 
+// Unwrap allows ParseError to be used with errors.Is and errors.As.
+func (e *ParseError) Unwrap() error { return e.Err }
+
 // FileReadError occurs when an error occurs when scanning the .env file.
 type FileReadError struct {
-	Filepath string
-	Err      error
+	Filepath  string
+	Err       error
+	FieldPath string // FieldPath is the field with the fromfile tag, if any.
+	Location  string
 }
 
 // Error satisfies the error interface for FileReadError.
 func (e *FileReadError) Error() string {
-	return fmt.Sprintf("reading %v: %v", e.Filepath, e.Err.Error())
+	return fmt.Sprintf("reading %v%v: %v", e.Filepath, errorContext(e.FieldPath, e.Location), e.Err.Error())
+}
+
+func (e *FileReadError) annotate(path, location string) {
+	setIfEmpty(&e.FieldPath, path)
+	setIfEmpty(&e.Location, location)
 }
 
 // Unwrap allows FileReadError to be used with errors.Is and errors.As.
@@ -165,3 +461,170 @@ func (e *IncompatibleOptionsError) Error() string {
 		e.Reason,
 	)
 }
+
+// annotatedError is implemented by errors relating to a single field, which are annotated with the path of the field
+// and the location of its value once returned from populating the field.
+type annotatedError interface {
+	annotate(path, location string)
+}
+
+// fieldError annotates err with the path of the field and the location of its value before wrapping it with msg, as
+// the message of the wrapped error is built when wrapping.
+func fieldError(msg string, err error, path, location string) error {
+	annotateError(err, path, location)
+
+	return fmt.Errorf("%v: %w", msg, err)
+}
+
+// annotateError annotates every annotatedError in the chain of err with path and location, keeping any path and
+// location already set by a more deeply nested field.
+func annotateError(err error, path, location string) {
+	if err == nil {
+		return
+	}
+
+	if a, ok := err.(annotatedError); ok {
+		a.annotate(path, location)
+	}
+
+	switch e := err.(type) { //nolint:errorlint // The chain is walked manually.
+	case interface{ Unwrap() error }:
+		annotateError(e.Unwrap(), path, location)
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			annotateError(err, path, location)
+		}
+	}
+}
+
+// setIfEmpty sets field to value, unless already set.
+func setIfEmpty(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
+
+// errorContext returns the field path and location for error messages, e.g. " (field Config.Port from env)".
+func errorContext(path, location string) string {
+	var parts []string
+
+	if path != "" {
+		parts = append(parts, "field "+path)
+	}
+
+	if location != "" {
+		parts = append(parts, "from "+location)
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return " (" + strings.Join(parts, " ") + ")"
+}
+
+// redactSecret hides a secret value from err, and from the typed errors it wraps, such as *FieldConversionError, which
+// remain available to errors.As.
+func redactSecret(err error, value string) error {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if conversionErr, ok := e.(*FieldConversionError); ok { //nolint:errorlint // The chain is walked manually.
+			conversionErr.Err = &redactedError{err: conversionErr.Err, value: value}
+		}
+	}
+
+	return &redactedError{err: err, value: value}
+}
+
+// redactedError hides a secret value from the message of the wrapped error, and from every error it unwraps to, so
+// that the value cannot be recovered using errors.Unwrap or errors.As, e.g. from a *strconv.NumError.
+type redactedError struct {
+	err   error
+	value string
+}
+
+// Error satisfies the error interface for redactedError.
+func (e *redactedError) Error() string {
+	return redactValue(e.err.Error(), e.value)
+}
+
+// Unwrap allows redactedError to be used with errors.Is and errors.As, returning the next error in the chain, also
+// redacted.
+func (e *redactedError) Unwrap() error {
+	next := errors.Unwrap(e.err)
+	if next == nil {
+		return nil
+	}
+
+	if redactedErr, ok := next.(*redactedError); ok { //nolint:errorlint // Avoids redacting twice.
+		return redactedErr
+	}
+
+	return &redactedError{err: next, value: e.value}
+}
+
+// Is allows sentinel errors wrapped by redactedError, such as strconv.ErrSyntax, to be matched by errors.Is.
+func (e *redactedError) Is(target error) bool {
+	return e.err == target //nolint:errorlint // Each error in the chain is compared by Unwrap.
+}
+
+// As allows the error types of this package wrapped by redactedError to be matched by errors.As, as their values are
+// redacted by redactSecret. Other error types may hold the secret value, and are not matched.
+func (e *redactedError) As(target any) bool {
+	if _, ok := e.err.(annotatedError); !ok {
+		return false
+	}
+
+	targetValue := reflect.ValueOf(target).Elem()
+	if !reflect.TypeOf(e.err).AssignableTo(targetValue.Type()) {
+		return false
+	}
+
+	targetValue.Set(reflect.ValueOf(e.err))
+
+	return true
+}
+
+func (e *redactedError) annotate(path, location string) {
+	if a, ok := e.err.(annotatedError); ok { //nolint:errorlint // The chain is walked by annotateError.
+		a.annotate(path, location)
+	}
+}
+
+// redactValue replaces every occurrence of value in message which is not part of a longer word, so that a short secret
+// such as 1 does not rewrite unrelated parts of the message.
+func redactValue(message, value string) string {
+	if value == "" {
+		return message
+	}
+
+	var b strings.Builder
+
+	for {
+		i := strings.Index(message, value)
+		if i < 0 {
+			b.WriteString(message)
+
+			return b.String()
+		}
+
+		end := i + len(value)
+		if wordBoundary(message, i-1) && wordBoundary(message, end) {
+			b.WriteString(message[:i] + redacted)
+		} else {
+			b.WriteString(message[:end])
+		}
+
+		message = message[end:]
+	}
+}
+
+// wordBoundary reports whether the byte at i in s is outside s, or not a letter, digit or underscore.
+func wordBoundary(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return true
+	}
+
+	c := s[i]
+
+	return !(c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z')
+}
@@ -2,6 +2,7 @@ package envconfig
 
 import (
 	"bufio"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -11,28 +12,52 @@ import (
 
 type source interface {
 	Load() (map[string]string, error)
+
+	// name returns the name of the source used by the sources tag, e.g. env.
+	name() string
+
+	// locate returns the values of the source, along with the location of each key within the source, e.g. env,
+	// config/prod.env:14 or flag --port.
+	locate() (values, locations map[string]string, err error)
 }
 
 type FlagSource struct{}
 
 func (s FlagSource) Load() (map[string]string, error) {
+	source, _, err := s.locate()
+
+	return source, err
+}
+
+func (s FlagSource) name() string { return sourceFlag }
+
+func (s FlagSource) locate() (map[string]string, map[string]string, error) {
 	flag.Parse()
 
 	source := make(map[string]string)
+	locations := make(map[string]string)
 
 	flag.Visit(func(f *flag.Flag) {
 		source[f.Name] = f.Value.String()
+		locations[f.Name] = "flag --" + f.Name
 	})
 
-	return source, nil
+	return source, locations, nil
 }
 
 const (
 	envExtension = ".env"
 )
 
+// Names of the sources, as used by the sources tag.
+const (
+	sourceEnv  = "env"
+	sourceFile = "file"
+	sourceFlag = "flag"
+)
+
 type parser interface {
-	parse() (map[string]string, error)
+	parse() (values, locations map[string]string, err error)
 }
 
 type FileSource struct {
@@ -40,18 +65,25 @@ type FileSource struct {
 }
 
 func (s FileSource) Load() (map[string]string, error) {
+	source, _, err := s.locate()
+
+	return source, err
+}
+
+func (s FileSource) name() string { return sourceFile }
+
+func (s FileSource) locate() (map[string]string, map[string]string, error) {
 	parser, err := identifyFileParser(s.filepath)
 	if err != nil {
-		return nil, fmt.Errorf("identify file parser: %w", err)
+		return nil, nil, fmt.Errorf("identify file parser: %w", err)
 	}
 
-	source, err := parser.parse()
+	source, locations, err := parser.parse()
 	if err != nil {
-		return nil, fmt.Errorf("parse file: %w", err)
+		return nil, nil, fmt.Errorf("parse file: %w", err)
 	}
 
-
-	return source, nil
+	return source, locations, nil
 }
 
 // identifyFileParser determines the parser to use based on the filepath received.
@@ -76,16 +108,19 @@ type envFileParser struct {
 	filepath string
 }
 
-func (e envFileParser) parse() (map[string]string, error) {
+func (e envFileParser) parse() (map[string]string, map[string]string, error) {
 	file, err := os.Open(filepath.Clean(e.filepath))
 	if err != nil {
-		return make(map[string]string), &OpenFileError{Err: err}
+		return make(map[string]string), nil, &OpenFileError{Err: err}
 	}
 	defer file.Close() //nolint:errcheck // File closure.
 
+	locations := make(map[string]string)
+
 	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
 		line := scanner.Text()
+		location := fmt.Sprintf("%v:%d", e.filepath, lineNumber)
 
 		// Handles empty and commented lines.
 		if line == "" || strings.HasPrefix(line, "#") {
@@ -94,24 +129,30 @@ func (e envFileParser) parse() (map[string]string, error) {
 
 		entry, err := e.parseLine(line)
 		if err != nil {
-			return make(map[string]string), fmt.Errorf("parse line: %w", err)
+			var parseErr *ParseError
+			if errors.As(err, &parseErr) {
+				parseErr.Location = location
+			}
+
+			return make(map[string]string), nil, fmt.Errorf("parse line: %w", err)
 		}
 
 		e.source[entry.key] = entry.value
+		locations[entry.key] = location
 	}
 
 	if err := scanner.Err(); err != nil {
-		return make(map[string]string), &FileReadError{Filepath: e.filepath, Err: err}
+		return make(map[string]string), nil, &FileReadError{Filepath: e.filepath, Err: err}
 	}
 
-	return e.source, nil
+	return e.source, locations, nil
 }
 
 // parseLine parses an individual .env line, and will detect comments.
 func (e envFileParser) parseLine(line string) (entry, error) {
 	key, value, found := strings.Cut(line, "=")
 	if !found {
-		return entry{}, &ParseError{Line: line}
+		return entry{}, &ParseError{Line: line, Err: ErrSyntax}
 	}
 
 	// Clean environment variable key.
@@ -130,7 +171,16 @@ type EnvironmentVariableSource struct {
 
 // processEnvironmentVariables populates the config struct using all environment variables.
 func (s EnvironmentVariableSource) Load() (map[string]string, error) { //nolint:gocognit // Complexity is reasonable.
+	source, _, err := s.locate()
+
+	return source, err
+}
+
+func (s EnvironmentVariableSource) name() string { return sourceEnv }
+
+func (s EnvironmentVariableSource) locate() (map[string]string, map[string]string, error) {
 	source := make(map[string]string)
+	locations := make(map[string]string)
 	all := os.Environ()
 
 	for _, val := range all {
@@ -140,7 +190,8 @@ func (s EnvironmentVariableSource) Load() (map[string]string, error) { //nolint:
 		}
 
 		source[key] = value
+		locations[key] = "env"
 	}
 
-	return source, nil
+	return source, locations, nil
 }
@@ -0,0 +1,33 @@
+package envconfig
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy derives the key of a config struct field from its Go field name, used with WithAutoKeys().
+type NamingStrategy func(fieldName string) string
+
+// SnakeUpper is a NamingStrategy which converts field names to upper snake case, keeping acronyms together, e.g.
+// ServerPort becomes SERVER_PORT, and HTTPTimeout becomes HTTP_TIMEOUT.
+func SnakeUpper(fieldName string) string {
+	runes := []rune(fieldName)
+
+	var key strings.Builder
+
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			previous := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+			// Start a new word after a lower case letter or digit, or at the last capital of an acronym.
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextLower) {
+				key.WriteByte('_')
+			}
+		}
+
+		key.WriteRune(unicode.ToUpper(r))
+	}
+
+	return key.String()
+}
@@ -0,0 +1,61 @@
+package envconfig_test
+
+import (
+	"testing"
+
+	"github.com/h-dav/envconfig/v3"
+)
+
+func TestSnakeUpper(t *testing.T) {
+	testCases := map[string]string{
+		"Port":        "PORT",
+		"ServerPort":  "SERVER_PORT",
+		"HTTPTimeout": "HTTP_TIMEOUT",
+		"UserID":      "USER_ID",
+		"TLS":         "TLS",
+		"MaxConns2":   "MAX_CONNS2",
+		"V2Endpoint":  "V2_ENDPOINT",
+		"serverPort":  "SERVER_PORT",
+	}
+
+	for fieldName, want := range testCases {
+		t.Run(fieldName, func(t *testing.T) {
+			t.Parallel()
+
+			if got := envconfig.SnakeUpper(fieldName); got != want {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestSetWithAutoKeys(t *testing.T) {
+	type Config struct {
+		AutoServerPort  int    `default:"8080"`
+		AutoHTTPTimeout string `env:"AUTO_EXPLICIT_TIMEOUT"`
+		AutoIgnored     string `env:"-"`
+		AutoDatabase    struct {
+			MaxConns int
+		}
+	}
+
+	t.Setenv("AUTO_HTTP_TIMEOUT", "ignored")
+	t.Setenv("AUTO_EXPLICIT_TIMEOUT", "5s")
+	t.Setenv("AUTO_IGNORED", "ignored")
+	t.Setenv("AUTO_DATABASE_MAX_CONNS", "20")
+
+	var config Config
+
+	if err := envconfig.Set(&config, envconfig.WithAutoKeys(envconfig.SnakeUpper)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var want Config
+	want.AutoServerPort = 8080
+	want.AutoHTTPTimeout = "5s"
+	want.AutoDatabase.MaxConns = 20
+
+	if config != want {
+		t.Errorf("got %+v, want %+v", config, want)
+	}
+}
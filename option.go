@@ -7,9 +7,26 @@ type settings struct {
 	activeProfile   string
 	prefix          string
 	source          map[string]string
+	locations       map[string]string        // locations holds where each key in source came from, e.g. env.
+	origins         map[string]string        // origins holds the name of the source of each key in source, e.g. file.
+	overridden      map[string][]SourceValue // overridden holds the values of each key replaced by later sources.
+	report          *Report
 	temporaryPrefix string // temporary prefix is only used we are populating nested structs
-	sources []source
-	decoders map[reflect.Type]DecoderFunc
+	sources         []source
+	decoders        map[reflect.Type]DecoderFunc
+	kinds           map[reflect.Type]map[string]reflect.Type
+	autoKeys        NamingStrategy
+	warn            func(warning error)
+	errs            *[]error                 // errs collects field errors, unless failFast is set.
+	consumed        map[string]bool          // consumed holds every key looked up while populating the config struct.
+	populating      map[nestedStructKey]bool // populating holds the structs being populated, to detect cycles.
+	embedded        bool                     // embedded is set while populating an embedded struct.
+	strictKeys      []string                 // strictKeys holds the keys which must be consumed when strict is set.
+	collisions      []*KeyCollisionError
+
+	normalizeKeys bool
+	failFast      bool
+	strict        bool
 }
 
 type option func(*settings)
@@ -33,7 +50,8 @@ func WithActiveProfile(activeProfile string) option {
 	}
 }
 
-// WithPrefix option will add the prefix to before every set and retrieval from env.
+// WithPrefix option will add the prefix before every key, including the keys of nested structs, aliases and text
+// replacement. Text replacement falls back to keys without the prefix.
 func WithPrefix(prefix string) option {
 	return func(s *settings) {
 		s.prefix = prefix
@@ -50,3 +68,75 @@ func WithDecoders(decoders map[reflect.Type]DecoderFunc) option {
 		}
 	}
 }
+
+// WithKinds option registers the concrete config struct types for an interface-typed field, keyed by the value of
+// the field's discriminator key.
+//
+//	type Config struct {
+//		Storage Storage `prefix:"STORAGE_" discriminator:"KIND"`
+//	}
+//
+//	envconfig.Set(&cfg, envconfig.WithKinds(reflect.TypeFor[Storage](), map[string]reflect.Type{
+//		"s3":    reflect.TypeFor[S3Config](),
+//		"local": reflect.TypeFor[*LocalConfig](),
+//	}))
+func WithKinds(iface reflect.Type, kinds map[string]reflect.Type) option {
+	return func(s *settings) {
+		if s.kinds == nil {
+			s.kinds = make(map[reflect.Type]map[string]reflect.Type)
+		}
+		if s.kinds[iface] == nil {
+			s.kinds[iface] = make(map[string]reflect.Type)
+		}
+		for kind, typ := range kinds {
+			s.kinds[iface][kind] = typ
+		}
+	}
+}
+
+// WithAutoKeys option derives the key of fields without an env tag, and the prefix of nested structs without a prefix
+// tag, from the Go field name using the naming strategy, e.g. WithAutoKeys(SnakeUpper).
+//
+// Fields can be excluded using `env:"-"`.
+func WithAutoKeys(naming NamingStrategy) option {
+	return func(s *settings) {
+		s.autoKeys = naming
+	}
+}
+
+// WithWarningHandler option provides a handler for non-fatal warnings, such as a *DeprecatedKeyWarning when a field is
+// populated using a deprecated alias.
+func WithWarningHandler(handler func(warning error)) option {
+	return func(s *settings) {
+		s.warn = handler
+	}
+}
+
+// WithFailFast option returns the first field error, rather than collecting every field error into an *AggregateError.
+func WithFailFast() option {
+	return func(s *settings) {
+		s.failFast = true
+	}
+}
+
+// WithStrict option reports an *UnknownKeyError for every key which is not consumed by a field of the config struct,
+// suggesting the closest known key, to catch typos such as SERVR_PORT. Every key from a file is checked, along with
+// environment variables under the prefix provided using WithPrefix().
+func WithStrict() option {
+	return func(s *settings) {
+		s.strict = true
+	}
+}
+
+// WithKeyNormalization option matches keys regardless of case, treating '.', '-' and '_' as equivalent, so that
+// server.port, server-port and SERVER_PORT are the same key.
+//
+// A *KeyCollisionError is returned if a source provides two distinct keys with different values which normalise to the
+// same key, when that key is used by a field or is under the prefix provided using WithPrefix(). Distinct keys with
+// the same value are allowed, as the value is unambiguous, and collisions between other keys, such as http_proxy and
+// HTTP_PROXY in the environment, are ignored.
+func WithKeyNormalization() option {
+	return func(s *settings) {
+		s.normalizeKeys = true
+	}
+}
@@ -1,6 +1,7 @@
 package envconfig_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/h-dav/envconfig/v3"
@@ -122,3 +123,217 @@ func TestSetWithFilepath(t *testing.T) {
 		)
 	}
 }
+
+func TestSetWithPrefixNamespace(t *testing.T) {
+	type Config struct {
+		Name     string `env:"NAME" required:"true"`
+		Endpoint string `env:"ENDPOINT"`
+		Database struct {
+			Host string `env:"HOST" aliases:"ADDR"`
+		} `prefix:"DB_"`
+	}
+
+	t.Setenv("NAMESPACE_REGION", "eu-west-2")
+	t.Setenv("TENANT_A_NAME", "alpha")
+	t.Setenv("TENANT_A_ENDPOINT", "https://${NAME}.${NAMESPACE_REGION}.example.com")
+	t.Setenv("TENANT_A_DB_HOST", "alpha.db")
+	t.Setenv("TENANT_B_NAME", "beta")
+	t.Setenv("TENANT_B_ENDPOINT", "https://${NAME}.example.com")
+	t.Setenv("TENANT_B_DB_ADDR", "beta.db")
+
+	var tenantA, tenantB Config
+
+	if err := envconfig.Set(&tenantA, envconfig.WithPrefix("TENANT_A_")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := envconfig.Set(&tenantB, envconfig.WithPrefix("TENANT_B_")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if tenantA.Name != "alpha" || tenantA.Endpoint != "https://alpha.eu-west-2.example.com" || tenantA.Database.Host != "alpha.db" {
+		t.Errorf("got %+v, want tenant A values", tenantA)
+	}
+
+	if tenantB.Name != "beta" || tenantB.Endpoint != "https://beta.example.com" || tenantB.Database.Host != "beta.db" {
+		t.Errorf("got %+v, want tenant B values", tenantB)
+	}
+
+	var tenantC Config
+
+	var requiredErr *envconfig.RequiredFieldError
+	if err := envconfig.Set(&tenantC, envconfig.WithPrefix("TENANT_C_")); !errors.As(err, &requiredErr) || requiredErr.FieldName != "TENANT_C_NAME" {
+		t.Errorf("got %v, want RequiredFieldError for TENANT_C_NAME", err)
+	}
+}
+
+func TestSetWithKeyNormalization(t *testing.T) {
+	type Config struct {
+		ServerPort int    `env:"NORM_SERVER_PORT"`
+		Region     string `env:"norm.region"`
+		Dup        string `env:"NORM_DUP"`
+		Log        struct {
+			Level string `env:"LEVEL"`
+		} `prefix:"NORM_LOG_"`
+	}
+
+	t.Run("success with equivalent keys", func(t *testing.T) {
+		var config Config
+
+		if err := envconfig.Set(
+			&config,
+			envconfig.WithKeyNormalization(),
+			envconfig.WithFilepath("./test_data/success_with_key_normalization.env"),
+		); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var want Config
+		want.ServerPort = 9090
+		want.Region = "eu-west-2"
+		want.Log.Level = "debug"
+
+		if config != want {
+			t.Errorf("got %+v, want %+v", config, want)
+		}
+	})
+
+	t.Run("failure with colliding keys", func(t *testing.T) {
+		var config Config
+
+		err := envconfig.Set(
+			&config,
+			envconfig.WithKeyNormalization(),
+			envconfig.WithFilepath("./test_data/failure_with_key_normalization_collision.env"),
+		)
+
+		var collisionErr *envconfig.KeyCollisionError
+		if !errors.As(err, &collisionErr) || collisionErr.Key != "NORM_DUP" {
+			t.Errorf("got %v, want KeyCollisionError for NORM_DUP", err)
+		}
+	})
+
+	t.Run("success with colliding keys unused by fields", func(t *testing.T) {
+		t.Setenv("norm_unused_proxy", "a")
+		t.Setenv("NORM_UNUSED_PROXY", "b")
+
+		var config Config
+
+		if err := envconfig.Set(&config, envconfig.WithKeyNormalization()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestSetWithAggregatedErrors(t *testing.T) {
+	type Config struct {
+		Host     string `env:"AGG_HOST,required"`
+		Port     int    `env:"AGG_PORT"`
+		Level    string `env:"AGG_LEVEL" oneof:"debug,info"`
+		Database struct {
+			Name string `env:"NAME,required"`
+		} `prefix:"AGG_DB_"`
+	}
+
+	t.Setenv("AGG_PORT", "not-a-port")
+	t.Setenv("AGG_LEVEL", "trace")
+
+	t.Run("every field error collected", func(t *testing.T) {
+		var config Config
+
+		err := envconfig.Set(&config)
+
+		var aggregateErr *envconfig.AggregateError
+		if !errors.As(err, &aggregateErr) {
+			t.Fatalf("got %v, want AggregateError", err)
+		}
+
+		if len(aggregateErr.Errs) != 4 {
+			t.Errorf("got %d errors, want 4: %v", len(aggregateErr.Errs), err)
+		}
+
+		var (
+			requiredErr   *envconfig.RequiredFieldError
+			conversionErr *envconfig.FieldConversionError
+			validationErr *envconfig.ValidationError
+		)
+
+		if !errors.As(err, &requiredErr) || !errors.As(err, &conversionErr) || !errors.As(err, &validationErr) {
+			t.Errorf("got %v, want each typed error to be matched", err)
+		}
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		var config Config
+
+		err := envconfig.Set(&config, envconfig.WithFailFast())
+
+		var (
+			aggregateErr *envconfig.AggregateError
+			requiredErr  *envconfig.RequiredFieldError
+		)
+
+		if errors.As(err, &aggregateErr) || !errors.As(err, &requiredErr) || requiredErr.FieldName != "AGG_HOST" {
+			t.Errorf("got %v, want only RequiredFieldError for AGG_HOST", err)
+		}
+	})
+}
+
+func TestSetWithStrict(t *testing.T) {
+	type Config struct {
+		Server struct {
+			Host string `env:"HOST"`
+			Port int    `env:"PORT" default:"8080"`
+		} `prefix:"SERVER_"`
+	}
+
+	t.Run("unknown keys from file and prefixed env", func(t *testing.T) {
+		t.Setenv("STRICT_SERVER_HSOT", "example.com")
+		t.Setenv("UNPREFIXED_UNKNOWN", "ignored")
+
+		var config Config
+
+		err := envconfig.Set(
+			&config,
+			envconfig.WithStrict(),
+			envconfig.WithPrefix("STRICT_"),
+			envconfig.WithFilepath("./test_data/failure_with_strict_unknown_keys.env"),
+		)
+
+		var aggregateErr *envconfig.AggregateError
+		if !errors.As(err, &aggregateErr) {
+			t.Fatalf("got %v, want AggregateError", err)
+		}
+
+		want := []envconfig.UnknownKeyError{
+			{Key: "STRICT_SERVER_HSOT", Location: "env", Suggestion: "STRICT_SERVER_HOST"},
+			{
+				Key:        "STRICT_SERVR_PORT",
+				Location:   "./test_data/failure_with_strict_unknown_keys.env:2",
+				Suggestion: "STRICT_SERVER_PORT",
+			},
+			{Key: "STRICT_UNRELATED_THING", Location: "./test_data/failure_with_strict_unknown_keys.env:3"},
+		}
+
+		if len(aggregateErr.Errs) != len(want) {
+			t.Fatalf("got %d errors, want %d: %v", len(aggregateErr.Errs), len(want), err)
+		}
+
+		for i, err := range aggregateErr.Errs {
+			var unknownErr *envconfig.UnknownKeyError
+			if !errors.As(err, &unknownErr) || *unknownErr != want[i] {
+				t.Errorf("got %v, want %+v", err, want[i])
+			}
+		}
+	})
+
+	t.Run("success without unknown keys", func(t *testing.T) {
+		t.Setenv("STRICT_SERVER_HOST", "example.com")
+
+		var config Config
+
+		if err := envconfig.Set(&config, envconfig.WithStrict(), envconfig.WithPrefix("STRICT_")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Report describes where the value of every field of a config struct came from, as returned by Explain.
+type Report struct {
+	Fields []FieldReport
+}
+
+// FieldReport describes where the value of a single field came from.
+type FieldReport struct {
+	FieldPath string
+	Key       string
+	Value     string // Value is the final value of the field, redacted for fields marked as secret.
+	Source    string // Source is env, file, flag or default, or empty if the field was not set.
+	Location  string
+	// Overridden holds the values provided by lower priority sources, including the default tag, lowest first.
+	Overridden []SourceValue
+}
+
+// SourceValue is a value provided for a key by a single source.
+type SourceValue struct {
+	Source   string
+	Location string
+	Value    string // Value is redacted for fields marked as secret.
+}
+
+// Explain populates the config struct in the same way as Set, and returns a Report describing where the value of
+// every field came from, which is useful when debugging which source set a value.
+//
+// The Report is returned even if populating the config struct fails, covering the fields populated successfully.
+func Explain(config any, opts ...option) (*Report, error) {
+	report := &Report{}
+
+	err := Set(config, append(opts, func(s *settings) { s.report = report })...)
+
+	return report, err
+}
+
+// String returns the Report with one field per line, e.g. Config.Port PORT=8080 (env, overrides file).
+func (r *Report) String() string {
+	var b strings.Builder
+
+	for _, field := range r.Fields {
+		source := field.Source
+		if source == "" {
+			source = "unset"
+		}
+
+		if field.Location != "" && field.Location != field.Source {
+			source += " " + field.Location
+		}
+
+		for _, overridden := range field.Overridden {
+			source += ", overrides " + overridden.Source
+		}
+
+		fmt.Fprintf(&b, "%v %v=%v (%v)\n", field.FieldPath, field.Key, field.Value, source)
+	}
+
+	return b.String()
+}
+
+// record adds the field to the report requested using Explain, if any.
+func (s settings) record(d fieldDescriptor, configFieldValue reflect.Value, origin valueOrigin) {
+	if s.report == nil {
+		return
+	}
+
+	redact := func(value string) string {
+		if d.secret {
+			return redacted
+		}
+
+		return value
+	}
+
+	var overridden []SourceValue
+
+	if origin.key != "" {
+		if d.defaultValue != "" {
+			overridden = append(overridden, SourceValue{
+				Source:   locationDefault,
+				Location: locationDefault,
+				Value:    redact(d.defaultValue),
+			})
+		}
+
+		for _, value := range s.overridden[s.canonicalKey(origin.key)] {
+			value.Value = redact(value.Value)
+			overridden = append(overridden, value)
+		}
+	}
+
+	s.report.Fields = append(s.report.Fields, FieldReport{
+		FieldPath:  d.path,
+		Key:        d.key,
+		Value:      redact(fieldString(configFieldValue)),
+		Source:     origin.source,
+		Location:   origin.location,
+		Overridden: overridden,
+	})
+}
@@ -0,0 +1,59 @@
+package envconfig_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/h-dav/envconfig/v3"
+)
+
+func TestExplain(t *testing.T) {
+	type Config struct {
+		Port     int    `env:"REPORT_PORT" default:"80"`
+		Password string `env:"REPORT_PASSWORD,secret"`
+		Level    string `env:"REPORT_LEVEL" default:"info"`
+		Optional string `env:"REPORT_OPTIONAL"`
+	}
+
+	t.Setenv("REPORT_PORT", "9090")
+
+	var config Config
+
+	report, err := envconfig.Explain(&config, envconfig.WithFilepath("./test_data/success_with_report.env"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []envconfig.FieldReport{
+		{
+			FieldPath: "Config.Port",
+			Key:       "REPORT_PORT",
+			Value:     "9090",
+			Source:    "env",
+			Location:  "env",
+			Overridden: []envconfig.SourceValue{
+				{Source: "default", Location: "default", Value: "80"},
+				{Source: "file", Location: "./test_data/success_with_report.env:1", Value: "8080"},
+			},
+		},
+		{
+			FieldPath: "Config.Password",
+			Key:       "REPORT_PASSWORD",
+			Value:     "[REDACTED]",
+			Source:    "file",
+			Location:  "./test_data/success_with_report.env:2",
+		},
+		{FieldPath: "Config.Level", Key: "REPORT_LEVEL", Value: "info", Source: "default", Location: "default"},
+		{FieldPath: "Config.Optional", Key: "REPORT_OPTIONAL"},
+	}
+
+	if !reflect.DeepEqual(report.Fields, want) {
+		t.Errorf("got %+v, want %+v", report.Fields, want)
+	}
+
+	if got := report.String(); strings.Contains(got, "file-secret") ||
+		!strings.Contains(got, "Config.Port REPORT_PORT=9090 (env, overrides default, overrides file)") {
+		t.Errorf("unexpected report:\n%v", got)
+	}
+}
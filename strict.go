@@ -0,0 +1,96 @@
+package envconfig
+
+import (
+	"slices"
+	"strings"
+)
+
+// strictSourceKeys returns the keys from a source which must be consumed when WithStrict() is used: every key from a
+// file, and environment variables under the prefix.
+func (s settings) strictSourceKeys(src source, values map[string]string) []string {
+	var keys []string
+
+	for key := range values {
+		switch src.(type) {
+		case FileSource:
+			keys = append(keys, key)
+		case EnvironmentVariableSource:
+			if s.prefix != "" && strings.HasPrefix(key, s.canonicalKey(s.prefix)) {
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	return keys
+}
+
+// unknownKeys returns an *UnknownKeyError for every strict key which was not consumed while populating the config
+// struct.
+func (s settings) unknownKeys() []error {
+	known := make([]string, 0, len(s.consumed))
+	for key := range s.consumed {
+		known = append(known, key)
+	}
+
+	slices.Sort(known)
+
+	var errs []error
+
+	for _, key := range slices.Compact(slices.Sorted(slices.Values(s.strictKeys))) {
+		if s.consumed[key] {
+			continue
+		}
+
+		errs = append(errs, &UnknownKeyError{
+			Key:        key,
+			Location:   s.locations[key],
+			Suggestion: suggestKey(key, known),
+		})
+	}
+
+	return errs
+}
+
+// suggestKey returns the known key closest to key by edit distance, or an empty string if none is close enough to be
+// a likely typo.
+func suggestKey(key string, known []string) string {
+	var suggestion string
+
+	// Allow roughly one edit for every three characters.
+	bestDistance := len(key)/3 + 2
+
+	for _, candidate := range known {
+		if distance := editDistance(key, candidate); distance < bestDistance {
+			suggestion, bestDistance = candidate, distance
+		}
+	}
+
+	return suggestion
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
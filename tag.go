@@ -2,8 +2,14 @@ package envconfig
 
 import (
 	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -22,48 +28,557 @@ const (
 
 	// tagPrefix is used for nested structs inside your config struct.
 	tagPrefix = "prefix"
+
+	// tagScheme is used to restrict the schemes accepted by url.URL and *url.URL fields.
+	tagScheme = "scheme"
+
+	// tagLayout is used to provide the layout for parsing time.Time fields.
+	tagLayout = "layout"
+
+	// tagEncoding is used to decode []byte fields, and supports base64, base64url, hex and raw (default).
+	tagEncoding = "encoding"
+
+	// tagDiscriminator is used for interface fields, and names the key which selects the concrete config struct type
+	// registered using WithKinds().
+	tagDiscriminator = "discriminator"
+
+	// tagFromFile is used for config struct fields whose value is a path to a file containing the actual value.
+	tagFromFile = "fromfile"
+
+	// tagSecret is used for config struct fields holding secrets, whose values are redacted from errors.
+	tagSecret = "secret"
+
+	// tagAliases is used to provide alternative keys for a field, e.g. the previous name of a renamed key. The first key
+	// set, starting with the env tag, is used.
+	tagAliases = "aliases"
+
+	// tagDeprecated is used to mark the aliases of a field as deprecated, reporting their usage to the warning handler
+	// provided using WithWarningHandler().
+	tagDeprecated = "deprecated"
+
+	// tagMin is used to validate the minimum of numbers and durations, or the minimum length of strings, slices and
+	// maps.
+	tagMin = "min"
+
+	// tagMax is used to validate the maximum of numbers and durations, or the maximum length of strings, slices and
+	// maps.
+	tagMax = "max"
+
+	// tagOneOf is used to validate that a field is one of a comma separated list of values.
+	tagOneOf = "oneof"
+
+	// tagPattern is used to validate that a field matches a regular expression.
+	tagPattern = "pattern"
+
+	// tagNonZero is used to validate that a field is not the zero value once populated.
+	tagNonZero = "nonzero"
+
+	// tagRequiredIf is used to require a field when another key has a given value, e.g. `required_if:"STORAGE=s3"`.
+	// Multiple comma separated conditions require the field when any of them is met.
+	tagRequiredIf = "required_if"
+
+	// tagRequiredWith is used to require a field when any of a comma separated list of other keys is set, e.g.
+	// `required_with:"TLS_CERT"`.
+	tagRequiredWith = "required_with"
+
+	// tagGroup is used to constrain how many fields of a named group within a struct are set, e.g.
+	// `group:"auth,exactly_one"`. Supported rules are exactly_one, at_most_one and at_least_one.
+	tagGroup = "group"
+
+	// tagSources is used to restrict the sources which may populate a field, e.g. `sources:"file"` for a value which
+	// must only be set by a config file. Supported sources are env, file and flag.
+	tagSources = "sources"
+
+	// tagSeparator is used to set the separator for slice fields, and is only available in the env tag, e.g.
+	// `env:"HOSTS,sep=;"`. Defaults to a comma.
+	tagSeparator = "sep"
 )
 
-// checkRequiredTag checks if a field is required and returns an error if so.
+// fieldDescriptor holds the options of a config struct field, parsed once from its tags.
 //
-// This function is only called when an environment variable is not set for a field.
-func checkRequiredTag(environmentVariableKey string, field reflect.StructField) error {
-	requiredOptionValue, requiredOptionSet := field.Tag.Lookup(tagRequired)
-	if !requiredOptionSet {
+// Options can be provided as separate tags, or in the compact form within the env tag, e.g.
+// `env:"PORT,required,default=8080"`. Options in the env tag take precedence over separate tags.
+type fieldDescriptor struct {
+	field        reflect.StructField
+	key          string // key includes the prefix of enclosing structs, and is empty if the env tag is not set.
+	path         string // path is the Go path of the field, e.g. Config.Server.Port.
+	ignored      bool   // ignored is set by `env:"-"`.
+	aliases      []string
+	deprecated   bool
+	required     bool
+	defaultValue string
+	separator    string
+	secret       bool
+	fromFile     bool
+	encoding     string
+	layout       string
+	min          string
+	max          string
+	oneOf        []string
+	pattern      *regexp.Regexp
+	nonZero      bool
+	prefix       string
+	requiredIf   []string
+	requiredWith []string
+	group        string
+	groupRule    string
+	sources      []string
+}
+
+// Group rules supported by the group tag.
+const (
+	groupExactlyOne = "exactly_one"
+	groupAtMostOne  = "at_most_one"
+	groupAtLeastOne = "at_least_one"
+)
+
+// parseFieldDescriptor parses the tags of a config struct field, whose keys are prefixed with prefix.
+//
+// When naming is provided, the key of a field without a key in its env tag is derived from the field name.
+func parseFieldDescriptor(field reflect.StructField, prefix string, naming NamingStrategy) (fieldDescriptor, error) {
+	name, options, _ := strings.Cut(field.Tag.Get(tagEnv), ",")
+	if name == "" && naming != nil {
+		name = naming(field.Name)
+	}
+
+	d := fieldDescriptor{
+		field:        field,
+		defaultValue: field.Tag.Get(tagDefault),
+		separator:    ",",
+		encoding:     field.Tag.Get(tagEncoding),
+		layout:       field.Tag.Get(tagLayout),
+		min:          field.Tag.Get(tagMin),
+		max:          field.Tag.Get(tagMax),
+		prefix:       prefix,
+		requiredIf:   splitTag(field.Tag.Get(tagRequiredIf)),
+		requiredWith: splitTag(field.Tag.Get(tagRequiredWith)),
+		sources:      splitTag(field.Tag.Get(tagSources)),
+	}
+
+	for _, source := range d.sources {
+		if !slices.Contains([]string{sourceEnv, sourceFile, sourceFlag}, source) {
+			return fieldDescriptor{}, d.optionError(tagSources, fmt.Errorf("%w: %v", ErrUnknownSource, source))
+		}
+	}
+
+	for _, condition := range d.requiredIf {
+		if !strings.Contains(condition, "=") {
+			return fieldDescriptor{}, d.optionError(tagRequiredIf, fmt.Errorf("%w: %v", ErrInvalidCondition, condition))
+		}
+	}
+
+	if group, ok := field.Tag.Lookup(tagGroup); ok {
+		d.group, d.groupRule, _ = strings.Cut(group, ",")
+		d.group, d.groupRule = strings.TrimSpace(d.group), strings.TrimSpace(d.groupRule)
+
+		if !slices.Contains([]string{groupExactlyOne, groupAtMostOne, groupAtLeastOne}, d.groupRule) {
+			return fieldDescriptor{}, d.optionError(tagGroup, fmt.Errorf("%w: %v", ErrUnknownGroupRule, d.groupRule))
+		}
+	}
+
+	if oneOf, ok := field.Tag.Lookup(tagOneOf); ok {
+		for _, value := range strings.Split(oneOf, ",") {
+			d.oneOf = append(d.oneOf, strings.TrimSpace(value))
+		}
+	}
+
+	if aliases := field.Tag.Get(tagAliases); aliases != "" {
+		for _, alias := range strings.Split(aliases, ",") {
+			d.aliases = append(d.aliases, prefix+strings.TrimSpace(alias))
+		}
+	}
+
+	switch name {
+	case "-":
+		d.ignored = true
+	case "":
+	default:
+		d.key = prefix + name
+	}
+
+	if pattern, ok := field.Tag.Lookup(tagPattern); ok {
+		var err error
+		if d.pattern, err = regexp.Compile(pattern); err != nil {
+			return fieldDescriptor{}, d.optionError(tagPattern, err)
+		}
+	}
+
+	for _, option := range []string{tagRequired, tagSecret, tagFromFile, tagDeprecated, tagNonZero} {
+		optionValue, optionSet := field.Tag.Lookup(option)
+		if !optionSet {
+			continue
+		}
+
+		if err := d.applyOption(option + "=" + optionValue); err != nil {
+			return fieldDescriptor{}, err
+		}
+	}
+
+	if options == "" {
+		return d, nil
+	}
+
+	// The default option takes the following values which are not options, so that default values can contain commas,
+	// e.g. slice defaults.
+	var defaultOption string
+
+	inDefault := false
+
+	for _, option := range strings.Split(options, ",") {
+		optionName, _, _ := strings.Cut(option, "=")
+		optionName = strings.TrimSpace(optionName)
+
+		switch {
+		case optionName == tagDefault:
+			defaultOption, inDefault = option, true
+
+			continue
+		case inDefault && !slices.Contains(compactOptions, optionName):
+			defaultOption += "," + option
+
+			continue
+		}
+
+		inDefault = false
+
+		if err := d.applyOption(option); err != nil {
+			return fieldDescriptor{}, err
+		}
+	}
+
+	if err := d.applyOption(defaultOption); err != nil {
+		return fieldDescriptor{}, err
+	}
+
+	return d, nil
+}
+
+// compactOptions holds the names of the options supported by the compact form of the env tag.
+var compactOptions = []string{
+	tagRequired, tagDefault, tagSeparator, tagSecret, tagFromFile, tagDeprecated, tagNonZero, tagEncoding, tagLayout,
+}
+
+// applyOption applies a single option from the compact form of the env tag, such as required or default=8080.
+func (d *fieldDescriptor) applyOption(option string) error {
+	optionName, optionValue, hasValue := strings.Cut(option, "=")
+	optionName = strings.TrimSpace(optionName)
+
+	flags := map[string]*bool{
+		tagRequired:   &d.required,
+		tagSecret:     &d.secret,
+		tagFromFile:   &d.fromFile,
+		tagDeprecated: &d.deprecated,
+		tagNonZero:    &d.nonZero,
+	}
+	if flag, ok := flags[optionName]; ok {
+		if !hasValue {
+			*flag = true
+
+			return nil
+		}
+
+		enabled, err := strconv.ParseBool(optionValue)
+		if err != nil {
+			return d.optionError(optionName, err)
+		}
+
+		*flag = enabled
+
 		return nil
 	}
 
-	requiredOption, err := strconv.ParseBool(requiredOptionValue)
-	if requiredOption {
+	switch optionName {
+	case "":
+		// Allows for trailing commas, e.g. `env:"PORT,"`.
+	case tagDefault:
+		d.defaultValue = optionValue
+	case tagSeparator:
+		if optionValue == "" {
+			return d.optionError(optionName, ErrEmptySeparator)
+		}
+
+		d.separator = optionValue
+	case tagEncoding:
+		d.encoding = optionValue
+	case tagLayout:
+		d.layout = optionValue
+	default:
+		return d.optionError(optionName, fmt.Errorf("%w: %q", ErrUnknownOption, optionName))
+	}
+
+	return nil
+}
+
+// optionError returns an InvalidOptionConversionError for an option of the field.
+func (d *fieldDescriptor) optionError(option string, err error) error {
+	fieldName := d.key
+	if fieldName == "" {
+		fieldName = d.field.Name
+	}
+
+	return &InvalidOptionConversionError{
+		FieldName: fieldName,
+		Option:    option,
+		Err:       err,
+	}
+}
+
+// splitTag splits a comma separated tag value, trimming spaces and dropping empty values.
+func splitTag(value string) []string {
+	var values []string
+
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+// checkRequiredTag checks if a field is required, either unconditionally or by the required_if and required_with tags
+// evaluated against settings.source, and returns an error if so.
+//
+// This function is only called when an environment variable is not set for a field.
+func (s settings) checkRequiredTag(environmentVariableKey string, d fieldDescriptor) error {
+	if d.required {
 		return &RequiredFieldError{FieldName: environmentVariableKey}
-	} else if err != nil {
+	}
+
+	for _, condition := range d.requiredIf {
+		key, value, _ := strings.Cut(condition, "=")
+		if resolved := s.relativeKey(d.prefix, key); s.lookup(resolved) == value {
+			return &RequiredFieldError{
+				FieldName: environmentVariableKey,
+				Condition: tagRequiredIf + " " + condition,
+				Location:  s.location(resolved),
+			}
+		}
+	}
+
+	for _, key := range d.requiredWith {
+		if resolved := s.relativeKey(d.prefix, key); s.lookup(resolved) != "" {
+			return &RequiredFieldError{
+				FieldName: environmentVariableKey,
+				Condition: tagRequiredWith + " " + key,
+				Location:  s.location(resolved),
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkSourcesTag returns an error if the source which provided key is not listed in the sources tag of the field.
+// Values from the default tag are always allowed.
+func (s settings) checkSourcesTag(key string, d fieldDescriptor) error {
+	if len(d.sources) == 0 || key == "" {
+		return nil
+	}
+
+	origin := s.origins[s.canonicalKey(key)]
+	if slices.Contains(d.sources, origin) {
+		return nil
+	}
+
+	return &SourceNotAllowedError{Key: key, Source: origin, AllowedSources: d.sources}
+}
+
+// relativeKey resolves a key referenced by a tag, which is relative to the prefix of the enclosing struct, falling back
+// to the key itself if the prefixed key is not set.
+func (s settings) relativeKey(prefix, key string) string {
+	if prefix == "" || s.lookup(prefix+key) != "" {
+		return prefix + key
+	}
+
+	return key
+}
+
+// checkGroupTags checks that the fields of configValue sharing a group tag satisfy the rule of the group, counting the
+// fields whose key, or one of its aliases, is set in settings.source.
+func (s settings) checkGroupTags(configValue reflect.Value, prefix, path string) error {
+	var (
+		groups    []string
+		rules     = map[string]string{}
+		keys      = map[string][]string{}
+		set       = map[string][]string{}
+		locations = map[string][]string{}
+	)
+
+	for i := range configValue.NumField() {
+		d, err := parseFieldDescriptor(configValue.Type().Field(i), prefix, s.autoKeys)
+		if err != nil || d.group == "" || d.ignored || d.key == "" {
+			continue
+		}
+
+		if _, ok := rules[d.group]; !ok {
+			groups = append(groups, d.group)
+			rules[d.group] = d.groupRule
+		}
+
+		keys[d.group] = append(keys[d.group], d.key)
+
+		for _, key := range append([]string{d.key}, d.aliases...) {
+			if s.lookup(key) != "" {
+				set[d.group] = append(set[d.group], key)
+				locations[d.group] = append(locations[d.group], s.location(key))
+
+				break
+			}
+		}
+	}
+
+	for _, group := range groups {
+		count := len(set[group])
+
+		violated := map[string]bool{
+			groupExactlyOne: count != 1,
+			groupAtMostOne:  count > 1,
+			groupAtLeastOne: count < 1,
+		}[rules[group]]
+
+		if violated {
+			return &GroupError{
+				Group:      group,
+				Rule:       rules[group],
+				Keys:       keys[group],
+				SetKeys:    set[group],
+				StructPath: path,
+				Locations:  locations[group],
+			}
+		}
+	}
+
+	return nil
+}
+
+// handleFromFileTag treats the value as a path and returns the contents of the file if the field has the fromfile
+// tag set.
+func handleFromFileTag(d fieldDescriptor, value string) (string, error) {
+	if !d.fromFile || value == "" {
+		return value, nil
+	}
+
+	contents, err := os.ReadFile(filepath.Clean(value))
+	if err != nil {
+		return "", &FileReadError{Filepath: value, Err: err}
+	}
+
+	return string(contents), nil
+}
+
+// checkSchemeTag checks that a populated URL field, or every element of a populated slice of URLs, uses one of the
+// schemes listed in its scheme tag.
+func checkSchemeTag(
+	environmentVariableKey string,
+	field reflect.StructField,
+	configFieldValue reflect.Value,
+	populated bool,
+) error {
+	schemeOptionValue, schemeOptionSet := field.Tag.Lookup(tagScheme)
+	if !schemeOptionSet {
+		return nil
+	}
+
+	urlType := field.Type
+	if urlType.Kind() == reflect.Slice {
+		urlType = urlType.Elem()
+	}
+
+	if urlType != reflect.TypeFor[url.URL]() && urlType != reflect.TypeFor[*url.URL]() {
 		return &InvalidOptionConversionError{
 			FieldName: environmentVariableKey,
-			Option:    tagRequired,
-			Err:       err,
+			Option:    tagScheme,
+			Err:       fmt.Errorf("%w: %v", ErrUnsupportedRule, field.Type),
+		}
+	}
+
+	if !populated {
+		return nil
+	}
+
+	allowed := strings.Split(schemeOptionValue, ",")
+	for i := range allowed {
+		allowed[i] = strings.TrimSpace(allowed[i])
+	}
+
+	urls := []reflect.Value{configFieldValue}
+	if configFieldValue.Kind() == reflect.Slice {
+		urls = nil
+		for i := range configFieldValue.Len() {
+			urls = append(urls, configFieldValue.Index(i))
+		}
+	}
+
+	for _, u := range urls {
+		if u.Kind() == reflect.Pointer && u.IsNil() {
+			continue
+		}
+
+		scheme := reflect.Indirect(u).FieldByName("Scheme").String()
+		if !slices.Contains(allowed, scheme) {
+			return &FieldConversionError{
+				FieldName:  environmentVariableKey,
+				TargetType: configFieldValue.Type().String(),
+				Err:        fmt.Errorf("%w: %q, expected one of %v", ErrSchemeNotAllowed, scheme, allowed),
+			}
 		}
 	}
 
 	return nil
 }
 
-func (s settings)handlePrefixTag(
+// handlePrefixTag populates nested config structs, pointers to nested config structs, interfaces selected by a
+// discriminator, and slices and maps of nested config structs, using the prefix tag.
+//
+// Embedded structs without a prefix tag are flattened into the parent struct.
+func (s settings) handlePrefixTag(
 	field reflect.StructField,
 	configFieldValue reflect.Value,
-	prefix string,
+	prefix, path string,
 ) error {
-	if field.Type.Kind() != reflect.Struct {
+	prefixOptionValue, prefixOptionSet := s.prefixTag(field)
+
+	switch {
+	case s.nestedStruct(field.Type):
+		if !prefixOptionSet && !field.Anonymous {
+			return &PrefixOptionError{FieldName: field.Name}
+		}
+
+		s.embedded = field.Anonymous
+
+		return s.populateNested(configFieldValue, prefix+prefixOptionValue, path)
+	case s.nestedStructPointer(field.Type):
+		if !prefixOptionSet && !field.Anonymous {
+			return &PrefixOptionError{FieldName: field.Name}
+		}
+
+		s.embedded = field.Anonymous
+
+		return s.populateNestedPointer(configFieldValue, prefix+prefixOptionValue, path)
+	case s.nestedKind(field):
+		return s.populateNestedKind(field, configFieldValue, prefix+prefixOptionValue, path)
+	case s.nestedCollection(field) && prefixOptionSet:
+		if field.Type.Kind() == reflect.Slice {
+			return s.populateNestedSlice(configFieldValue, prefix+prefixOptionValue, path)
+		}
+
+		return s.populateNestedMap(configFieldValue, prefix+prefixOptionValue, path)
+	default:
 		return nil
 	}
+}
 
-	prefixOptionValue, prefixOptionSet := field.Tag.Lookup(tagPrefix)
-	if !prefixOptionSet {
-		return &PrefixOptionError{FieldName: field.Name}
+// prefixTag returns the prefix tag of a field. When WithAutoKeys() is used, the prefix of fields without a prefix
+// tag is derived from the field name, except for embedded structs which are flattened.
+func (s settings) prefixTag(field reflect.StructField) (string, bool) {
+	if prefixOptionValue, prefixOptionSet := field.Tag.Lookup(tagPrefix); prefixOptionSet {
+		return prefixOptionValue, true
 	}
 
-	if err := s.populateNestedConfig(configFieldValue, prefix+prefixOptionValue); err != nil {
-		return fmt.Errorf("populate nested config struct: %w", err)
+	if s.autoKeys != nil && !field.Anonymous {
+		return s.autoKeys(field.Name) + "_", true
 	}
 
-	return nil
+	return "", false
 }
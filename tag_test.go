@@ -0,0 +1,372 @@
+package envconfig_test
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/h-dav/envconfig/v3"
+)
+
+func TestSetSuccessWithCompactTags(t *testing.T) {
+	type Config struct {
+		Port     int         `env:"COMPACT_PORT,required,default=8080"`
+		Hosts    []string    `env:"COMPACT_HOSTS,sep=;"`
+		Ports    []int       `env:"COMPACT_PORTS,sep=|"`
+		Days     []time.Time `env:"COMPACT_DAYS,sep=;,layout=2006-01-02"`
+		Key      []byte      `env:"COMPACT_KEY,encoding=hex,secret"`
+		Optional string      `env:"COMPACT_OPTIONAL,required=false"`
+		Legacy   string      `env:"COMPACT_LEGACY" default:"legacy"`
+		Override string      `env:"COMPACT_OVERRIDE,default=compact" default:"separate"`
+		Defaults []string    `env:"COMPACT_DEFAULTS,secret,default=a,b"`
+	}
+
+	t.Setenv("COMPACT_PORT", "9090")
+	t.Setenv("COMPACT_HOSTS", "a.internal;b.internal")
+	t.Setenv("COMPACT_PORTS", "80|443")
+	t.Setenv("COMPACT_DAYS", "2024-01-01;2024-01-02")
+	t.Setenv("COMPACT_KEY", "beef")
+
+	var config Config
+
+	if err := envconfig.Set(&config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := Config{
+		Port:     9090,
+		Hosts:    []string{"a.internal", "b.internal"},
+		Ports:    []int{80, 443},
+		Days:     []time.Time{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		Key:      []byte{0xbe, 0xef},
+		Legacy:   "legacy",
+		Override: "compact",
+		Defaults: []string{"a", "b"},
+	}
+
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("got %+v, want %+v", config, want)
+	}
+}
+
+func TestExplainWithCompactTagOptionsAfterDefault(t *testing.T) {
+	type Config struct {
+		Port  int      `env:"PORT,required,default=8080,sep=;,secret"`
+		Hosts []string `env:"COMPACT_AFTER_DEFAULT_HOSTS,default=a,b,sep=;"`
+	}
+
+	t.Setenv("PORT", "9090")
+
+	var config Config
+
+	report, err := envconfig.Explain(&config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if config.Port != 9090 || !reflect.DeepEqual(config.Hosts, []string{"a,b"}) {
+		t.Errorf("got %+v, want port 9090 and hosts [a,b]", config)
+	}
+
+	if got := report.Fields[0].Value; got != "[REDACTED]" {
+		t.Errorf("got %q, want [REDACTED]", got)
+	}
+}
+
+func TestSetFailureWithCompactTags(t *testing.T) {
+	t.Run("missing required field", func(t *testing.T) {
+		var config struct {
+			Value string `env:"COMPACT_MISSING,required"`
+		}
+
+		var requiredErr *envconfig.RequiredFieldError
+		if err := envconfig.Set(&config); !errors.As(err, &requiredErr) {
+			t.Errorf("got %v, want RequiredFieldError", err)
+		}
+	})
+
+	t.Run("unknown option", func(t *testing.T) {
+		var config struct {
+			Value string `env:"COMPACT_UNKNOWN,optional"`
+		}
+
+		if err := envconfig.Set(&config); !errors.Is(err, envconfig.ErrUnknownOption) {
+			t.Errorf("got %v, want %v", err, envconfig.ErrUnknownOption)
+		}
+	})
+
+	t.Run("secret redacted from conversion error", func(t *testing.T) {
+		t.Setenv("COMPACT_SECRET", "hunter2")
+
+		var config struct {
+			Value int `env:"COMPACT_SECRET,secret"`
+		}
+
+		err := envconfig.Set(&config)
+
+		var conversionErr *envconfig.FieldConversionError
+		if !errors.As(err, &conversionErr) {
+			t.Fatalf("got %v, want FieldConversionError", err)
+		}
+
+		if strings.Contains(err.Error(), "hunter2") {
+			t.Errorf("got %q, want secret value redacted", err.Error())
+		}
+
+		if strings.Contains(conversionErr.Error(), "hunter2") {
+			t.Errorf("got %q, want secret value redacted from FieldConversionError", conversionErr.Error())
+		}
+
+		for e := error(conversionErr); e != nil; e = errors.Unwrap(e) {
+			if strings.Contains(e.Error(), "hunter2") {
+				t.Errorf("got %q, want secret value redacted from wrapped errors", e.Error())
+			}
+		}
+
+		var numErr *strconv.NumError
+		if errors.As(err, &numErr) {
+			t.Errorf("got %v, want strconv.NumError holding the secret value to be unreachable", numErr)
+		}
+
+		if !errors.Is(err, strconv.ErrSyntax) {
+			t.Errorf("got %v, want %v", err, strconv.ErrSyntax)
+		}
+	})
+
+	t.Run("short secret redacted without rewriting message", func(t *testing.T) {
+		t.Setenv("COMPACT_SHORT_SECRET_1", "x")
+
+		var config struct {
+			Value int `env:"COMPACT_SHORT_SECRET_1,secret"`
+		}
+
+		err := envconfig.Set(&config)
+		if err == nil {
+			t.Fatal("got nil, want error")
+		}
+
+		if !strings.Contains(err.Error(), "COMPACT_SHORT_SECRET_1") || strings.Contains(err.Error(), `"x"`) {
+			t.Errorf("got %q, want key intact and secret value redacted", err.Error())
+		}
+	})
+}
+
+func TestSetWithAliases(t *testing.T) {
+	type Config struct {
+		DatabaseURL string `env:"ALIAS_DATABASE_URL" aliases:"ALIAS_DB_URL, ALIAS_POSTGRES_URL" deprecated:"true"`
+		CacheURL    string `env:"ALIAS_CACHE_URL" aliases:"ALIAS_REDIS_URL"`
+	}
+
+	testCases := map[string]struct {
+		env          map[string]string
+		want         Config
+		wantWarnings []envconfig.DeprecatedKeyWarning
+	}{
+		"primary key wins": {
+			env: map[string]string{
+				"ALIAS_DATABASE_URL": "postgres://new",
+				"ALIAS_DB_URL":       "postgres://old",
+			},
+			want: Config{DatabaseURL: "postgres://new"},
+		},
+		"first present alias wins": {
+			env: map[string]string{
+				"ALIAS_DB_URL":       "postgres://old",
+				"ALIAS_POSTGRES_URL": "postgres://older",
+				"ALIAS_REDIS_URL":    "redis://cache",
+			},
+			want: Config{DatabaseURL: "postgres://old", CacheURL: "redis://cache"},
+			wantWarnings: []envconfig.DeprecatedKeyWarning{
+				{
+					Key:         "ALIAS_DB_URL",
+					Replacement: "ALIAS_DATABASE_URL",
+					FieldPath:   "Config.DatabaseURL",
+					Location:    "env",
+				},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			var (
+				config   Config
+				warnings []envconfig.DeprecatedKeyWarning
+			)
+
+			if err := envconfig.Set(&config, envconfig.WithWarningHandler(func(warning error) {
+				var deprecatedWarning *envconfig.DeprecatedKeyWarning
+				if errors.As(warning, &deprecatedWarning) {
+					warnings = append(warnings, *deprecatedWarning)
+				}
+			})); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if config != tc.want {
+				t.Errorf("got %+v, want %+v", config, tc.want)
+			}
+
+			if !reflect.DeepEqual(warnings, tc.wantWarnings) {
+				t.Errorf("got warnings %+v, want %+v", warnings, tc.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestSetWithConditionalRequirements(t *testing.T) {
+	type Config struct {
+		Storage string `env:"STORAGE"`
+		S3      struct {
+			Bucket string `env:"BUCKET" required_if:"STORAGE=s3"`
+		} `prefix:"COND_S3_"`
+		Cert      string `env:"COND_TLS_CERT"`
+		Key       string `env:"COND_TLS_KEY" required_with:"COND_TLS_CERT"`
+		Token     string `env:"COND_TOKEN" group:"auth,exactly_one"`
+		TokenFile string `env:"COND_TOKEN_FILE" group:"auth,exactly_one"`
+	}
+
+	testCases := map[string]struct {
+		env       map[string]string
+		wantErr   bool
+		condition string
+		group     bool
+	}{
+		"conditions not met": {
+			env: map[string]string{"STORAGE": "disk", "COND_TOKEN": "token"},
+		},
+		"conditions met and satisfied": {
+			env: map[string]string{
+				"STORAGE":         "s3",
+				"COND_S3_BUCKET":  "bucket",
+				"COND_TLS_CERT":   "cert",
+				"COND_TLS_KEY":    "key",
+				"COND_TOKEN_FILE": "/run/token",
+			},
+		},
+		"required if": {
+			env:       map[string]string{"STORAGE": "s3", "COND_TOKEN": "token"},
+			wantErr:   true,
+			condition: "required_if STORAGE=s3",
+		},
+		"required with": {
+			env:       map[string]string{"COND_TLS_CERT": "cert", "COND_TOKEN": "token"},
+			wantErr:   true,
+			condition: "required_with COND_TLS_CERT",
+		},
+		"group with none set": {
+			env:     map[string]string{},
+			wantErr: true,
+			group:   true,
+		},
+		"group with both set": {
+			env:     map[string]string{"COND_TOKEN": "token", "COND_TOKEN_FILE": "/run/token"},
+			wantErr: true,
+			group:   true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			var config Config
+
+			err := envconfig.Set(&config)
+			if !tc.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			if tc.group {
+				var groupErr *envconfig.GroupError
+				if !errors.As(err, &groupErr) || groupErr.Group != "auth" {
+					t.Errorf("got %v, want GroupError for group auth", err)
+				}
+
+				return
+			}
+
+			var requiredErr *envconfig.RequiredFieldError
+			if !errors.As(err, &requiredErr) || requiredErr.Condition != tc.condition || requiredErr.Location != "env" {
+				t.Errorf("got %v, want RequiredFieldError with condition %q from env", err, tc.condition)
+			}
+		})
+	}
+}
+
+func TestSetFailureWithInvalidConditionalTags(t *testing.T) {
+	t.Run("invalid condition", func(t *testing.T) {
+		var config struct {
+			Value string `env:"COND_INVALID" required_if:"STORAGE"`
+		}
+
+		if err := envconfig.Set(&config); !errors.Is(err, envconfig.ErrInvalidCondition) {
+			t.Errorf("got %v, want %v", err, envconfig.ErrInvalidCondition)
+		}
+	})
+
+	t.Run("unknown group rule", func(t *testing.T) {
+		var config struct {
+			Value string `env:"COND_INVALID" group:"auth,exactly_two"`
+		}
+
+		if err := envconfig.Set(&config); !errors.Is(err, envconfig.ErrUnknownGroupRule) {
+			t.Errorf("got %v, want %v", err, envconfig.ErrUnknownGroupRule)
+		}
+	})
+}
+
+func TestSetWithSourcesTag(t *testing.T) {
+	type Config struct {
+		Signed string `env:"RESTRICTED_SIGNED" sources:"file"`
+		Token  string `env:"RESTRICTED_TOKEN" sources:"env,file" default:"none"`
+	}
+
+	t.Run("allowed sources", func(t *testing.T) {
+		var config Config
+
+		if err := envconfig.Set(&config, envconfig.WithFilepath("./test_data/success_with_sources_tag.env")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if want := (Config{Signed: "from-file", Token: "none"}); config != want {
+			t.Errorf("got %+v, want %+v", config, want)
+		}
+	})
+
+	t.Run("disallowed source", func(t *testing.T) {
+		t.Setenv("RESTRICTED_SIGNED", "from-env")
+
+		var config Config
+
+		err := envconfig.Set(&config, envconfig.WithFilepath("./test_data/success_with_sources_tag.env"))
+
+		var sourceErr *envconfig.SourceNotAllowedError
+		if !errors.As(err, &sourceErr) || sourceErr.Source != "env" || sourceErr.FieldPath != "Config.Signed" {
+			t.Errorf("got %v, want SourceNotAllowedError for env", err)
+		}
+	})
+
+	t.Run("unknown source", func(t *testing.T) {
+		var config struct {
+			Value string `env:"RESTRICTED_VALUE" sources:"vault"`
+		}
+
+		if err := envconfig.Set(&config); !errors.Is(err, envconfig.ErrUnknownSource) {
+			t.Errorf("got %v, want %v", err, envconfig.ErrUnknownSource)
+		}
+	})
+}
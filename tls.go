@@ -0,0 +1,223 @@
+package envconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TLS is a ready-made nested config struct for TLS settings, which builds a validated *tls.Config once populated.
+//
+// Certificates, keys and CA bundles can be provided either as paths to PEM files, or as inline PEM. Cipher suites
+// listed by tls.InsecureCipherSuites() are rejected unless InsecureCipherSuites is set.
+//
+//	type Config struct {
+//		TLS envconfig.TLS `prefix:"TLS_"`
+//	}
+type TLS struct {
+	CertFile             string        `env:"CERT_FILE"`
+	KeyFile              string        `env:"KEY_FILE"`
+	CAFile               string        `env:"CA_FILE"`
+	Cert                 string        `env:"CERT"`
+	Key                  string        `env:"KEY" secret:"true"`
+	CA                   string        `env:"CA"`
+	ClientAuth           TLSClientAuth `env:"CLIENT_AUTH"`
+	MinVersion           TLSVersion    `env:"MIN_VERSION"`
+	MaxVersion           TLSVersion    `env:"MAX_VERSION"`
+	CipherSuites         []string      `env:"CIPHER_SUITES"`
+	InsecureCipherSuites bool          `env:"INSECURE_CIPHER_SUITES"`
+
+	config *tls.Config
+}
+
+// Config returns the *tls.Config built from the populated settings, or nil if the struct has not been populated.
+func (t *TLS) Config() *tls.Config {
+	return t.config
+}
+
+// build validates the populated settings and builds the *tls.Config.
+func (t *TLS) build(prefix string) error {
+	if t.MinVersion != 0 && t.MaxVersion != 0 && t.MinVersion > t.MaxVersion {
+		return &FieldConversionError{
+			FieldName:  prefix + "MIN_VERSION",
+			TargetType: "TLSVersion",
+			Err: fmt.Errorf("%w: %s > %s", ErrInvalidTLSVersionRange,
+				tls.VersionName(uint16(t.MinVersion)), tls.VersionName(uint16(t.MaxVersion))),
+		}
+	}
+
+	config := &tls.Config{ //nolint:gosec // MinVersion is configurable, and defaults to the crypto/tls default.
+		ClientAuth: tls.ClientAuthType(t.ClientAuth),
+		MinVersion: uint16(t.MinVersion),
+		MaxVersion: uint16(t.MaxVersion),
+	}
+
+	certPEM, err := pemValue(t.CertFile, t.Cert)
+	if err != nil {
+		return err
+	}
+
+	keyPEM, err := pemValue(t.KeyFile, t.Key)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(certPEM) != 0 && len(keyPEM) == 0:
+		return &RequiredFieldError{FieldName: prefix + "KEY_FILE"}
+	case len(certPEM) == 0 && len(keyPEM) != 0:
+		return &RequiredFieldError{FieldName: prefix + "CERT_FILE"}
+	case len(certPEM) != 0:
+		certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return &FieldConversionError{
+				FieldName:  prefix + "CERT_FILE",
+				TargetType: "tls.Certificate",
+				Err:        err,
+			}
+		}
+
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	caPEM, err := pemValue(t.CAFile, t.CA)
+	if err != nil {
+		return err
+	}
+
+	if len(caPEM) != 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return &FieldConversionError{
+				FieldName:  prefix + "CA_FILE",
+				TargetType: "*x509.CertPool",
+				Err:        ErrNoCertificates,
+			}
+		}
+
+		config.RootCAs = pool
+		config.ClientCAs = pool
+	}
+
+	// Without a CA, client certificates would be verified against the system roots.
+	verifyClientCerts := config.ClientAuth == tls.VerifyClientCertIfGiven ||
+		config.ClientAuth == tls.RequireAndVerifyClientCert
+	if verifyClientCerts && len(caPEM) == 0 {
+		return &RequiredFieldError{FieldName: prefix + "CA_FILE"}
+	}
+
+	config.CipherSuites, err = cipherSuiteIDs(t.CipherSuites, t.InsecureCipherSuites)
+	if err != nil {
+		return &FieldConversionError{
+			FieldName:  prefix + "CIPHER_SUITES",
+			TargetType: "[]uint16",
+			Err:        err,
+		}
+	}
+
+	t.config = config
+
+	return nil
+}
+
+// pemValue returns the contents of the file at path if provided, otherwise the inline PEM.
+func pemValue(path, inline string) ([]byte, error) {
+	if path == "" {
+		return []byte(inline), nil
+	}
+
+	contents, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, &FileReadError{Filepath: path, Err: err}
+	}
+
+	return contents, nil
+}
+
+// cipherSuiteIDs converts cipher suite names, as named by crypto/tls, into their IDs. Insecure cipher suites are
+// rejected unless allowInsecure is set.
+func cipherSuiteIDs(names []string, allowInsecure bool) ([]uint16, error) {
+	suites := map[string]uint16{}
+	for _, suite := range tls.CipherSuites() {
+		suites[suite.Name] = suite.ID
+	}
+
+	insecure := map[string]uint16{}
+	for _, suite := range tls.InsecureCipherSuites() {
+		insecure[suite.Name] = suite.ID
+	}
+
+	var ids []uint16
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		id, ok := suites[name]
+		if !ok {
+			if id, ok = insecure[name]; !ok {
+				return nil, fmt.Errorf("%w: %q", ErrUnknownCipherSuite, name)
+			}
+
+			if !allowInsecure {
+				return nil, fmt.Errorf("%w: %q", ErrInsecureCipherSuite, name)
+			}
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// TLSVersion is a TLS protocol version, which can be populated from values such as 1.2 or 1.3.
+type TLSVersion uint16
+
+// UnmarshalText allows TLSVersion to be used as a config field type.
+func (v *TLSVersion) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "":
+		*v = 0
+	case "1.0":
+		*v = tls.VersionTLS10
+	case "1.1":
+		*v = tls.VersionTLS11
+	case "1.2":
+		*v = tls.VersionTLS12
+	case "1.3":
+		*v = tls.VersionTLS13
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownTLSVersion, text)
+	}
+
+	return nil
+}
+
+// TLSClientAuth is a TLS client authentication policy, which can be populated from the values none, request,
+// require, verify_if_given and require_and_verify.
+type TLSClientAuth tls.ClientAuthType
+
+// UnmarshalText allows TLSClientAuth to be used as a config field type.
+func (c *TLSClientAuth) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "", "none":
+		*c = TLSClientAuth(tls.NoClientCert)
+	case "request":
+		*c = TLSClientAuth(tls.RequestClientCert)
+	case "require":
+		*c = TLSClientAuth(tls.RequireAnyClientCert)
+	case "verify_if_given":
+		*c = TLSClientAuth(tls.VerifyClientCertIfGiven)
+	case "require_and_verify":
+		*c = TLSClientAuth(tls.RequireAndVerifyClientCert)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownClientAuth, text)
+	}
+
+	return nil
+}
@@ -0,0 +1,244 @@
+package envconfig_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/h-dav/envconfig/v3"
+)
+
+// writeTestCertificate writes a self-signed certificate and key to a temporary directory, returning their paths.
+func writeTestCertificate(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "envconfig"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certPath, keyPath := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	if err := os.WriteFile(certPath, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return certPath, keyPath
+}
+
+func TestSetSuccessWithTLSConfig(t *testing.T) {
+	type Config struct {
+		TLS envconfig.TLS `prefix:"TLS_SUCCESS_"`
+	}
+
+	certPath, keyPath := writeTestCertificate(t)
+
+	t.Setenv("TLS_SUCCESS_CERT_FILE", certPath)
+	t.Setenv("TLS_SUCCESS_KEY_FILE", keyPath)
+	t.Setenv("TLS_SUCCESS_CA_FILE", certPath)
+	t.Setenv("TLS_SUCCESS_CLIENT_AUTH", "require_and_verify")
+	t.Setenv("TLS_SUCCESS_MIN_VERSION", "1.2")
+	t.Setenv("TLS_SUCCESS_CIPHER_SUITES", "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256")
+
+	var config Config
+
+	if err := envconfig.Set(&config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tlsConfig := config.TLS.Config()
+	if tlsConfig == nil {
+		t.Fatal("got nil *tls.Config")
+	}
+
+	if len(tlsConfig.Certificates) != 1 || tlsConfig.ClientCAs == nil || tlsConfig.RootCAs == nil {
+		t.Errorf("got %+v, want certificate and CA pools", tlsConfig)
+	}
+
+	if tlsConfig.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Errorf("got %v, want %v", tlsConfig.ClientAuth, tls.RequireAndVerifyClientCert)
+	}
+
+	if tlsConfig.MinVersion != tls.VersionTLS12 {
+		t.Errorf("got %v, want %v", tlsConfig.MinVersion, tls.VersionTLS12)
+	}
+
+	if len(tlsConfig.CipherSuites) != 1 || tlsConfig.CipherSuites[0] != tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256 {
+		t.Errorf("got %v, want TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", tlsConfig.CipherSuites)
+	}
+}
+
+func TestSetFailureWithTLSConfig(t *testing.T) {
+	type Config struct {
+		TLS envconfig.TLS `prefix:"TLS_FAILURE_"`
+	}
+
+	certPath, _ := writeTestCertificate(t)
+
+	testCases := map[string]struct {
+		env     map[string]string
+		want    any
+		wantErr error
+	}{
+		"cert without key": {
+			env:  map[string]string{"TLS_FAILURE_CERT_FILE": certPath},
+			want: new(*envconfig.RequiredFieldError),
+		},
+		"missing cert file": {
+			env: map[string]string{
+				"TLS_FAILURE_CERT_FILE": "./test_data/does_not_exist.pem",
+				"TLS_FAILURE_KEY_FILE":  "./test_data/does_not_exist.pem",
+			},
+			want: new(*envconfig.FileReadError),
+		},
+		"invalid inline CA": {
+			env:  map[string]string{"TLS_FAILURE_CA": "not a certificate"},
+			want: new(*envconfig.FieldConversionError),
+		},
+		"unknown min version": {
+			env:  map[string]string{"TLS_FAILURE_MIN_VERSION": "2.0"},
+			want: new(*envconfig.FieldConversionError),
+		},
+		"unknown cipher suite": {
+			env:  map[string]string{"TLS_FAILURE_CIPHER_SUITES": "TLS_NOT_A_SUITE"},
+			want: new(*envconfig.FieldConversionError),
+		},
+		"verified client auth without CA": {
+			env:  map[string]string{"TLS_FAILURE_CLIENT_AUTH": "require_and_verify"},
+			want: new(*envconfig.RequiredFieldError),
+		},
+		"min version above max version": {
+			env: map[string]string{
+				"TLS_FAILURE_MIN_VERSION": "1.3",
+				"TLS_FAILURE_MAX_VERSION": "1.2",
+			},
+			want:    new(*envconfig.FieldConversionError),
+			wantErr: envconfig.ErrInvalidTLSVersionRange,
+		},
+		"insecure cipher suite": {
+			env:     map[string]string{"TLS_FAILURE_CIPHER_SUITES": "TLS_RSA_WITH_RC4_128_SHA"},
+			want:    new(*envconfig.FieldConversionError),
+			wantErr: envconfig.ErrInsecureCipherSuite,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			var config Config
+
+			err := envconfig.Set(&config)
+			if !errors.As(err, tc.want) {
+				t.Errorf("got %v, want %T", err, tc.want)
+			}
+
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Errorf("got %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestExplainRedactsTLSKey(t *testing.T) {
+	type Config struct {
+		TLS envconfig.TLS `prefix:"TLS_REPORT_"`
+	}
+
+	certPath, keyPath := writeTestCertificate(t)
+
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		t.Fatalf("read certificate: %v", err)
+	}
+
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		t.Fatalf("read key: %v", err)
+	}
+
+	t.Setenv("TLS_REPORT_CERT", string(certPEM))
+	t.Setenv("TLS_REPORT_KEY", string(keyPEM))
+
+	var config Config
+
+	report, err := envconfig.Explain(&config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, field := range report.Fields {
+		if field.Key == "TLS_REPORT_KEY" && field.Value != "[REDACTED]" {
+			t.Errorf("got %q, want [REDACTED]", field.Value)
+		}
+	}
+
+	if strings.Contains(report.String(), "PRIVATE KEY") {
+		t.Errorf("got report containing the private key:\n%v", report)
+	}
+}
+
+func TestSetSuccessWithRootTLSConfig(t *testing.T) {
+	t.Setenv("TLS_ROOT_MIN_VERSION", "1.2")
+	t.Setenv("TLS_ROOT_MAX_VERSION", "1.2")
+	t.Setenv("TLS_ROOT_CIPHER_SUITES", "TLS_RSA_WITH_RC4_128_SHA")
+	t.Setenv("TLS_ROOT_INSECURE_CIPHER_SUITES", "true")
+
+	var config envconfig.TLS
+
+	if err := envconfig.Set(&config, envconfig.WithPrefix("TLS_ROOT_")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tlsConfig := config.Config()
+	if tlsConfig == nil {
+		t.Fatal("got nil *tls.Config")
+	}
+
+	if tlsConfig.MinVersion != tls.VersionTLS12 || tlsConfig.MaxVersion != tls.VersionTLS12 {
+		t.Errorf("got %v-%v, want %v", tlsConfig.MinVersion, tlsConfig.MaxVersion, tls.VersionTLS12)
+	}
+
+	if len(tlsConfig.CipherSuites) != 1 || tlsConfig.CipherSuites[0] != tls.TLS_RSA_WITH_RC4_128_SHA {
+		t.Errorf("got %v, want TLS_RSA_WITH_RC4_128_SHA", tlsConfig.CipherSuites)
+	}
+}
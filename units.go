@@ -0,0 +1,194 @@
+package envconfig
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ByteSize is a number of bytes, which can be populated from human-friendly values such as 512KiB, 10MB or 1.5GiB.
+type ByteSize uint64
+
+// Decimal and binary byte size units.
+const (
+	Byte ByteSize = 1
+
+	KB ByteSize = 1000 * Byte
+	MB ByteSize = 1000 * KB
+	GB ByteSize = 1000 * MB
+	TB ByteSize = 1000 * GB
+	PB ByteSize = 1000 * TB
+
+	KiB ByteSize = 1024 * Byte
+	MiB ByteSize = 1024 * KiB
+	GiB ByteSize = 1024 * MiB
+	TiB ByteSize = 1024 * GiB
+	PiB ByteSize = 1024 * TiB
+)
+
+// byteSizeUnits maps lower case unit suffixes to their size.
+var byteSizeUnits = map[string]ByteSize{
+	"":    Byte,
+	"b":   Byte,
+	"kb":  KB,
+	"mb":  MB,
+	"gb":  GB,
+	"tb":  TB,
+	"pb":  PB,
+	"kib": KiB,
+	"mib": MiB,
+	"gib": GiB,
+	"tib": TiB,
+	"pib": PiB,
+}
+
+// ParseByteSize parses a byte size such as 512KiB, 10MB, 1.5GiB or 1024. Units are case-insensitive, and a value
+// without a unit is a number of bytes.
+func ParseByteSize(value string) (ByteSize, error) {
+	trimmed := strings.TrimSpace(value)
+
+	number := strings.TrimRightFunc(trimmed, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	unit := strings.ToLower(strings.TrimSpace(trimmed[len(number):]))
+
+	multiplier, ok := byteSizeUnits[unit]
+	if !ok || number == "" {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidByteSize, value)
+	}
+
+	size, err := strconv.ParseFloat(number, 64)
+	if err != nil || size < 0 || math.IsNaN(size) || math.IsInf(size, 0) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidByteSize, value)
+	}
+
+	bytes := size * float64(multiplier)
+	if bytes >= math.MaxUint64 {
+		return 0, fmt.Errorf("%w: %q overflows", ErrInvalidByteSize, value)
+	}
+
+	return ByteSize(bytes), nil
+}
+
+// UnmarshalText allows ByteSize to be used as a config field type.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	size, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+
+	*b = size
+
+	return nil
+}
+
+// DecodeByteSize is a DecoderFunc for ByteSize, which can be registered using WithDecoders().
+func DecodeByteSize(key, value string) (reflect.Value, error) {
+	size, err := ParseByteSize(value)
+	if err != nil {
+		return reflect.Value{}, &FieldConversionError{
+			FieldName:  key,
+			TargetType: "envconfig.ByteSize",
+			Err:        err,
+		}
+	}
+
+	return reflect.ValueOf(size), nil
+}
+
+// Duration is a time.Duration which can also be populated using days and weeks, such as 7d or 2w.
+type Duration time.Duration
+
+// durationComponentRegex matches a single number and unit pair of a duration, such as 1.5h.
+var durationComponentRegex = regexp.MustCompile(`(\d+\.?\d*|\.\d+)([a-zµμ]*)`)
+
+// extendedDurationUnits maps the units not supported by time.ParseDuration to their length.
+var extendedDurationUnits = map[string]time.Duration{
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// ParseDuration parses a duration in the format accepted by time.ParseDuration, with the additional units d (days)
+// and w (weeks), such as 7d, 2w or 1w2d12h.
+func ParseDuration(value string) (time.Duration, error) {
+	sign, components := "", value
+	if strings.HasPrefix(components, "-") || strings.HasPrefix(components, "+") {
+		sign, components = components[:1], components[1:]
+	}
+
+	var converted strings.Builder
+
+	converted.WriteString(sign)
+
+	matched := 0
+
+	for _, match := range durationComponentRegex.FindAllStringSubmatchIndex(components, -1) {
+		if match[0] != matched {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, value)
+		}
+
+		matched = match[1]
+		number, unit := components[match[2]:match[3]], components[match[4]:match[5]]
+
+		length, ok := extendedDurationUnits[unit]
+		if !ok {
+			converted.WriteString(number + unit)
+
+			continue
+		}
+
+		count, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, value)
+		}
+
+		converted.WriteString(strconv.FormatFloat(count*length.Hours(), 'f', -1, 64) + "h")
+	}
+
+	if matched != len(components) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, value)
+	}
+
+	duration, err := time.ParseDuration(converted.String())
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, value)
+	}
+
+	return duration, nil
+}
+
+// UnmarshalText allows Duration to be used as a config field type.
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(duration)
+
+	return nil
+}
+
+// String returns the duration formatted by time.Duration.
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// DecodeDuration is a DecoderFunc for time.Duration accepting days and weeks, which can be registered using
+// WithDecoders() to replace the default time.Duration decoder.
+func DecodeDuration(key, value string) (reflect.Value, error) {
+	duration, err := ParseDuration(value)
+	if err != nil {
+		return reflect.Value{}, &FieldConversionError{
+			FieldName:  key,
+			TargetType: "time.Duration",
+			Err:        err,
+		}
+	}
+
+	return reflect.ValueOf(duration), nil
+}
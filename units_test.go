@@ -0,0 +1,107 @@
+package envconfig_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/h-dav/envconfig/v3"
+)
+
+func TestParseByteSize(t *testing.T) {
+	testCases := map[string]struct {
+		value   string
+		want    envconfig.ByteSize
+		wantErr error
+	}{
+		"bytes without unit": {value: "10485760", want: 10485760},
+		"bytes with unit":    {value: "512B", want: 512},
+		"binary unit":        {value: "512KiB", want: 512 * envconfig.KiB},
+		"decimal unit":       {value: "10MB", want: 10 * envconfig.MB},
+		"fractional value":   {value: "1.5GiB", want: 1536 * envconfig.MiB},
+		"lower case unit":    {value: "2 gib", want: 2 * envconfig.GiB},
+		"unknown unit":       {value: "10XB", wantErr: envconfig.ErrInvalidByteSize},
+		"missing number":     {value: "MB", wantErr: envconfig.ErrInvalidByteSize},
+		"negative":           {value: "-1", wantErr: envconfig.ErrInvalidByteSize},
+		"negative with unit": {value: "-5MB", wantErr: envconfig.ErrInvalidByteSize},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := envconfig.ParseByteSize(tc.value)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+
+			if got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	testCases := map[string]struct {
+		value   string
+		want    time.Duration
+		wantErr error
+	}{
+		"standard duration": {value: "1h30m", want: 90 * time.Minute},
+		"days":              {value: "7d", want: 7 * 24 * time.Hour},
+		"weeks":             {value: "2w", want: 14 * 24 * time.Hour},
+		"fractional days":   {value: "1.5d", want: 36 * time.Hour},
+		"mixed units":       {value: "1w2d12h", want: 9*24*time.Hour + 12*time.Hour},
+		"negative":          {value: "-1d", want: -24 * time.Hour},
+		"missing unit":      {value: "10", wantErr: envconfig.ErrInvalidDuration},
+		"unknown unit":      {value: "3y", wantErr: envconfig.ErrInvalidDuration},
+		"trailing garbage":  {value: "1d!", wantErr: envconfig.ErrInvalidDuration},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := envconfig.ParseDuration(tc.value)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+
+			if got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSetSuccessWithUnitFields(t *testing.T) {
+	type Config struct {
+		MaxBody   envconfig.ByteSize `env:"UNITS_MAX_BODY"`
+		Retention envconfig.Duration `env:"UNITS_RETENTION"`
+		Timeout   time.Duration      `env:"UNITS_TIMEOUT"`
+	}
+
+	t.Setenv("UNITS_MAX_BODY", "10MiB")
+	t.Setenv("UNITS_RETENTION", "2w")
+	t.Setenv("UNITS_TIMEOUT", "1d")
+
+	var config Config
+
+	if err := envconfig.Set(&config, envconfig.WithDecoders(map[reflect.Type]envconfig.DecoderFunc{
+		reflect.TypeOf(time.Duration(0)): envconfig.DecodeDuration,
+	})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := Config{
+		MaxBody:   10 * envconfig.MiB,
+		Retention: envconfig.Duration(14 * 24 * time.Hour),
+		Timeout:   24 * time.Hour,
+	}
+
+	if config != want {
+		t.Errorf("got %+v, want %+v", config, want)
+	}
+}
@@ -0,0 +1,156 @@
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validator is implemented by config structs with invariants spanning several fields, such as a minimum which must not
+// exceed a maximum. Validate is called on the config struct and every nested struct once populated, bottom-up. The
+// Validate method of an embedded struct is promoted, so it is only called through the struct embedding it.
+type Validator interface {
+	Validate() error
+}
+
+// redacted replaces the values of fields marked as secret.
+const redacted = "[REDACTED]"
+
+// validateField checks a field against its validation tags once populated.
+//
+// Rules other than nonzero are skipped for fields which were not populated and still hold the zero value.
+func validateField(d fieldDescriptor, configFieldValue reflect.Value, populated bool) error {
+	if d.nonZero && configFieldValue.IsZero() {
+		return d.validationError(tagNonZero, configFieldValue)
+	}
+
+	if !populated && configFieldValue.IsZero() {
+		return nil
+	}
+
+	for _, bound := range []struct {
+		rule, limit string
+		violated    func(value, limit float64) bool
+	}{
+		{tagMin, d.min, func(value, limit float64) bool { return value < limit }},
+		{tagMax, d.max, func(value, limit float64) bool { return value > limit }},
+	} {
+		if bound.limit == "" {
+			continue
+		}
+
+		value, limit, err := measure(configFieldValue, bound.limit)
+		if err != nil {
+			return d.optionError(bound.rule, err)
+		}
+
+		if bound.violated(value, limit) {
+			return d.validationError(bound.rule+"="+bound.limit, configFieldValue)
+		}
+	}
+
+	for _, value := range elements(configFieldValue) {
+		if len(d.oneOf) != 0 && !slices.Contains(d.oneOf, value) {
+			return d.validationError(tagOneOf+"="+strings.Join(d.oneOf, ","), configFieldValue)
+		}
+
+		if d.pattern != nil && !d.pattern.MatchString(value) {
+			return d.validationError(tagPattern+"="+d.pattern.String(), configFieldValue)
+		}
+	}
+
+	return nil
+}
+
+// validateStruct calls Validate on configValue if it implements Validator, wrapping any failure with path.
+func validateStruct(configValue reflect.Value, path string) error {
+	if !configValue.Addr().CanInterface() {
+		return nil
+	}
+
+	v, ok := configValue.Addr().Interface().(Validator)
+	if !ok {
+		return nil
+	}
+
+	if err := v.Validate(); err != nil {
+		return &StructValidationError{StructPath: path, Err: err}
+	}
+
+	return nil
+}
+
+// measure returns the value of a number or duration field, or the length of a string, slice or map field, along with
+// the limit parsed to be comparable with it.
+func measure(configFieldValue reflect.Value, limit string) (float64, float64, error) {
+	configFieldValue = reflect.Indirect(configFieldValue)
+
+	if typ := configFieldValue.Type(); typ == reflect.TypeFor[time.Duration]() || typ == reflect.TypeFor[Duration]() {
+		duration, err := ParseDuration(limit)
+
+		return float64(configFieldValue.Int()), float64(duration), err
+	}
+
+	var value float64
+
+	switch configFieldValue.Kind() { //nolint:exhaustive // Remaining kinds are not supported.
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = float64(configFieldValue.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = float64(configFieldValue.Uint())
+	case reflect.Float32, reflect.Float64:
+		value = configFieldValue.Float()
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		value = float64(configFieldValue.Len())
+	default:
+		return 0, 0, fmt.Errorf("%w: %v", ErrUnsupportedRule, configFieldValue.Type())
+	}
+
+	limitValue, err := strconv.ParseFloat(limit, 64)
+
+	return value, limitValue, err //nolint:wrapcheck // Wrapped by the caller.
+}
+
+// elements returns the string form of each element of a slice field, or of the field itself.
+func elements(configFieldValue reflect.Value) []string {
+	configFieldValue = reflect.Indirect(configFieldValue)
+
+	if configFieldValue.Kind() != reflect.Slice || configFieldValue.Type().Elem().Kind() == reflect.Uint8 {
+		return []string{fmt.Sprint(configFieldValue.Interface())}
+	}
+
+	values := make([]string, configFieldValue.Len())
+	for i := range values {
+		values[i] = fmt.Sprint(configFieldValue.Index(i).Interface())
+	}
+
+	return values
+}
+
+// fieldString returns the string form of a field, dereferencing pointers.
+func fieldString(configFieldValue reflect.Value) string {
+	configFieldValue = reflect.Indirect(configFieldValue)
+	if !configFieldValue.IsValid() {
+		return "<nil>"
+	}
+
+	return fmt.Sprint(configFieldValue.Interface())
+}
+
+// validationError returns a ValidationError for the field, redacting the value of secrets.
+func (d *fieldDescriptor) validationError(rule string, configFieldValue reflect.Value) error {
+	value := redacted
+	if !d.secret {
+		value = fieldString(configFieldValue)
+	}
+
+	return &ValidationError{
+		FieldPath: d.path,
+		Key:       d.key,
+		Rule:      rule,
+		Value:     value,
+	}
+}
@@ -0,0 +1,283 @@
+package envconfig_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/h-dav/envconfig/v3"
+)
+
+func TestSetSuccessWithValidationTags(t *testing.T) {
+	type Config struct {
+		Port     int           `env:"VALIDATE_PORT" min:"1" max:"65535"`
+		Timeout  time.Duration `env:"VALIDATE_TIMEOUT" min:"1s" max:"1m"`
+		Level    string        `env:"VALIDATE_LEVEL" oneof:"debug,info,warn"`
+		Name     string        `env:"VALIDATE_NAME" pattern:"^[a-z]+$" min:"3"`
+		Hosts    []string      `env:"VALIDATE_HOSTS" min:"1" max:"3" oneof:"a,b,c"`
+		Optional int           `env:"VALIDATE_OPTIONAL" min:"10"`
+	}
+
+	t.Setenv("VALIDATE_PORT", "8080")
+	t.Setenv("VALIDATE_TIMEOUT", "30s")
+	t.Setenv("VALIDATE_LEVEL", "info")
+	t.Setenv("VALIDATE_NAME", "service")
+	t.Setenv("VALIDATE_HOSTS", "a,c")
+
+	var config Config
+
+	if err := envconfig.Set(&config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSetFailureWithValidationTags(t *testing.T) {
+	tests := map[string]struct {
+		key, value string
+		config     any
+		rule       string
+	}{
+		"below min": {
+			key: "VALIDATE_FAIL_PORT", value: "0",
+			config: &struct {
+				Port int `env:"VALIDATE_FAIL_PORT" min:"1"`
+			}{},
+			rule: "min=1",
+		},
+		"above max duration": {
+			key: "VALIDATE_FAIL_TIMEOUT", value: "2d",
+			config: &struct {
+				Timeout envconfig.Duration `env:"VALIDATE_FAIL_TIMEOUT" max:"1d"`
+			}{},
+			rule: "max=1d",
+		},
+		"not one of": {
+			key: "VALIDATE_FAIL_LEVEL", value: "trace",
+			config: &struct {
+				Level string `env:"VALIDATE_FAIL_LEVEL" oneof:"debug,info,warn"`
+			}{},
+			rule: "oneof=debug,info,warn",
+		},
+		"pattern mismatch": {
+			key: "VALIDATE_FAIL_NAME", value: "Service",
+			config: &struct {
+				Name string `env:"VALIDATE_FAIL_NAME" pattern:"^[a-z]+$"`
+			}{},
+			rule: "pattern=^[a-z]+$",
+		},
+		"too many elements": {
+			key: "VALIDATE_FAIL_HOSTS", value: "a,b,c",
+			config: &struct {
+				Hosts []string `env:"VALIDATE_FAIL_HOSTS" max:"2"`
+			}{},
+			rule: "max=2",
+		},
+		"zero value": {
+			key: "VALIDATE_FAIL_UNSET", value: "",
+			config: &struct {
+				Name string `env:"VALIDATE_FAIL_UNSET,nonzero"`
+			}{},
+			rule: "nonzero",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(test.key, test.value)
+
+			var validationErr *envconfig.ValidationError
+			if err := envconfig.Set(test.config); !errors.As(err, &validationErr) {
+				t.Fatalf("got %v, want ValidationError", err)
+			}
+
+			if validationErr.Key != test.key || validationErr.Rule != test.rule {
+				t.Errorf("got key %q and rule %q, want key %q and rule %q",
+					validationErr.Key, validationErr.Rule, test.key, test.rule)
+			}
+		})
+	}
+
+	t.Run("field path and redacted secret", func(t *testing.T) {
+		t.Setenv("VALIDATE_DB_PASSWORD", "short")
+
+		var config struct {
+			Database struct {
+				Password string `env:"PASSWORD,secret" min:"8"`
+			} `prefix:"VALIDATE_DB_"`
+		}
+
+		var validationErr *envconfig.ValidationError
+		if err := envconfig.Set(&config); !errors.As(err, &validationErr) {
+			t.Fatalf("got %v, want ValidationError", err)
+		}
+
+		if validationErr.FieldPath != "Database.Password" {
+			t.Errorf("got field path %q, want %q", validationErr.FieldPath, "Database.Password")
+		}
+
+		if strings.Contains(validationErr.Error(), "short") {
+			t.Errorf("secret value leaked in error: %v", validationErr)
+		}
+	})
+
+	t.Run("unsupported rule", func(t *testing.T) {
+		t.Setenv("VALIDATE_UNSUPPORTED", "true")
+
+		var config struct {
+			Enabled bool `env:"VALIDATE_UNSUPPORTED" min:"1"`
+		}
+
+		if err := envconfig.Set(&config); !errors.Is(err, envconfig.ErrUnsupportedRule) {
+			t.Errorf("got %v, want %v", err, envconfig.ErrUnsupportedRule)
+		}
+	})
+}
+
+var errMinExceedsMax = errors.New("min connections exceeds max connections")
+
+type validatedPool struct {
+	MinConns int `env:"MIN_CONNS"`
+	MaxConns int `env:"MAX_CONNS"`
+}
+
+func (p *validatedPool) Validate() error {
+	if p.MinConns > p.MaxConns {
+		return errMinExceedsMax
+	}
+
+	return nil
+}
+
+type validatedConfig struct {
+	Pool  validatedPool `prefix:"VALIDATOR_POOL_"`
+	order *[]string
+}
+
+func (c *validatedConfig) Validate() error {
+	*c.order = append(*c.order, "Config")
+
+	return nil
+}
+
+func TestSetWithValidator(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		t.Setenv("VALIDATOR_POOL_MIN_CONNS", "1")
+		t.Setenv("VALIDATOR_POOL_MAX_CONNS", "10")
+
+		var order []string
+
+		config := validatedConfig{order: &order}
+
+		if err := envconfig.Set(&config); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(order) != 1 {
+			t.Errorf("got %d calls to Validate on the config struct, want 1", len(order))
+		}
+	})
+
+	t.Run("nested failure wrapped with struct path", func(t *testing.T) {
+		t.Setenv("VALIDATOR_POOL_MIN_CONNS", "20")
+		t.Setenv("VALIDATOR_POOL_MAX_CONNS", "10")
+
+		var order []string
+
+		config := validatedConfig{order: &order}
+
+		err := envconfig.Set(&config)
+
+		var structErr *envconfig.StructValidationError
+		if !errors.As(err, &structErr) || !errors.Is(err, errMinExceedsMax) {
+			t.Fatalf("got %v, want StructValidationError wrapping %v", err, errMinExceedsMax)
+		}
+
+		if structErr.StructPath != "validatedConfig.Pool" {
+			t.Errorf("got struct path %q, want %q", structErr.StructPath, "validatedConfig.Pool")
+		}
+
+		if len(order) != 0 {
+			t.Errorf("config struct validated before nested struct")
+		}
+	})
+}
+
+type ValidatedServer struct {
+	Port  int `env:"VALIDATOR_EMBEDDED_PORT"`
+	calls *int
+}
+
+func (s *ValidatedServer) Validate() error {
+	*s.calls++
+
+	return nil
+}
+
+type promotedValidatorConfig struct {
+	ValidatedServer
+}
+
+type pointerPromotedValidatorConfig struct {
+	*ValidatedServer
+}
+
+type shadowedValidatorConfig struct {
+	ValidatedServer
+
+	calls *int
+}
+
+func (c *shadowedValidatorConfig) Validate() error {
+	*c.calls++
+
+	return nil
+}
+
+func TestSetWithEmbeddedValidator(t *testing.T) {
+	t.Setenv("VALIDATOR_EMBEDDED_PORT", "8080")
+
+	t.Run("promoted", func(t *testing.T) {
+		var calls int
+
+		config := promotedValidatorConfig{ValidatedServer: ValidatedServer{calls: &calls}}
+
+		if err := envconfig.Set(&config); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if calls != 1 {
+			t.Errorf("got %d calls to Validate on the embedded struct, want 1", calls)
+		}
+	})
+
+	t.Run("promoted from pointer", func(t *testing.T) {
+		var calls int
+
+		config := pointerPromotedValidatorConfig{ValidatedServer: &ValidatedServer{calls: &calls}}
+
+		if err := envconfig.Set(&config); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if calls != 1 {
+			t.Errorf("got %d calls to Validate on the embedded struct, want 1", calls)
+		}
+	})
+
+	// Like any shadowed method, the Validate method of the embedded struct is left to the one shadowing it.
+	t.Run("shadowed", func(t *testing.T) {
+		var embeddedCalls, calls int
+
+		config := shadowedValidatorConfig{ValidatedServer: ValidatedServer{calls: &embeddedCalls}, calls: &calls}
+
+		if err := envconfig.Set(&config); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if embeddedCalls != 0 || calls != 1 {
+			t.Errorf("got %d and %d calls to Validate on the embedded and config structs, want 0 and 1",
+				embeddedCalls, calls)
+		}
+	})
+}